@@ -19,12 +19,13 @@ var testClient *Client
 var testRepository string
 
 // TestMain initializes a shared client using CURSOR_API_KEY and optional env config.
+// Without an API key only the integration tests are skipped.
 func TestMain(m *testing.M) {
 	loadDotEnv(".env")
 	cfg, _ := ConfigFromEnv()
 	if cfg.APIKey == "" {
-		fmt.Fprintln(os.Stderr, "CURSOR_API_KEY must be set for integration tests")
-		os.Exit(2)
+		fmt.Fprintln(os.Stderr, "CURSOR_API_KEY is not set; skipping integration tests")
+		os.Exit(m.Run())
 	}
 	// Identify tests via a dedicated User-Agent.
 	cfg.UserAgent = "cursor-go-sdk-tests"
@@ -44,6 +45,14 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+// requireLive skips t unless integration tests are enabled.
+func requireLive(t *testing.T) {
+	t.Helper()
+	if testClient == nil {
+		t.Skip("CURSOR_API_KEY must be set for integration tests")
+	}
+}
+
 func loadDotEnv(path string) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -109,6 +118,7 @@ func parseOwnerRepo(remote string) string {
 }
 
 func TestMeEndpoint(t *testing.T) {
+	requireLive(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	start := time.Now()
@@ -128,6 +138,7 @@ func TestMeEndpoint(t *testing.T) {
 }
 
 func TestListRepositoriesEndpoint(t *testing.T) {
+	requireLive(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 	start := time.Now()
@@ -144,6 +155,7 @@ func TestListRepositoriesEndpoint(t *testing.T) {
 }
 
 func TestListModelsEndpoint(t *testing.T) {
+	requireLive(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 	start := time.Now()
@@ -160,6 +172,7 @@ func TestListModelsEndpoint(t *testing.T) {
 }
 
 func TestAgentsStatusEndpoints(t *testing.T) {
+	requireLive(t)
 	require.NotEmpty(t, testRepository, "repository owner/name required; set CURSOR_TEST_REPOSITORY if autodetect fails")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
@@ -201,6 +214,7 @@ func TestAgentsStatusEndpoints(t *testing.T) {
 }
 
 func TestAgentsRunFlowEndpoints(t *testing.T) {
+	requireLive(t)
 	require.NotEmpty(t, testRepository, "repository owner/name required; set CURSOR_TEST_REPOSITORY if autodetect fails")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
@@ -0,0 +1,84 @@
+package cursor
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HeaderIdempotencyKey is the request header carrying an idempotency key.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// CallOption configures a single API call. Every Client method accepts
+// call options after its regular arguments.
+type CallOption func(*CallOptions)
+
+// CallOptions holds the settings made by call options. Client applies them;
+// other API implementations, such as mocks, can inspect them with NewCallOptions.
+type CallOptions struct {
+	Meta           *ResponseMeta // set by WithResponseMeta
+	Timeout        time.Duration // set by WithCallTimeout
+	Header         http.Header   // set by WithCallHeader
+	BaseURL        string        // set by WithCallBaseURL
+	APIKey         string        // set by WithCallAPIKey
+	NoRetries      bool          // set by WithoutRetries
+	IdempotencyKey string        // set by WithIdempotencyKey
+}
+
+// NewCallOptions applies opts in order and returns the resulting settings.
+func NewCallOptions(opts ...CallOption) CallOptions {
+	var o CallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCallTimeout limits the whole call, including retries, to d.
+func WithCallTimeout(d time.Duration) CallOption {
+	return func(o *CallOptions) { o.Timeout = d }
+}
+
+// WithCallHeader adds a request header to the call. It may be repeated.
+func WithCallHeader(key, value string) CallOption {
+	return func(o *CallOptions) {
+		if o.Header == nil {
+			o.Header = http.Header{}
+		}
+		o.Header.Add(key, value)
+	}
+}
+
+// WithCallBaseURL sends the call to baseURL instead of the client's base URL.
+func WithCallBaseURL(baseURL string) CallOption {
+	return func(o *CallOptions) { o.BaseURL = baseURL }
+}
+
+// WithCallAPIKey authenticates the call with apiKey instead of the client's key.
+func WithCallAPIKey(apiKey string) CallOption {
+	return func(o *CallOptions) { o.APIKey = apiKey }
+}
+
+// WithoutRetries makes a single attempt even if the client has a RetryPolicy.
+func WithoutRetries() CallOption {
+	return func(o *CallOptions) { o.NoRetries = true }
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header, so that the
+// server performs a repeated request only once. Requests with a key are
+// retried on 5xx responses like GET and DELETE requests.
+//
+// LaunchAgent generates a key automatically when retries are enabled.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *CallOptions) { o.IdempotencyKey = key }
+}
+
+// newIdempotencyKey returns a random (version 4) UUID.
+func newIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // Client is the entrypoint for interacting with the Cursor Background Agents API.
@@ -16,6 +18,15 @@ type Client struct {
 	httpClient *http.Client
 	apiKey     string
 	userAgent  string
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	middleware []Middleware
+	doer       Doer
+
+	logger        *slog.Logger
+	logLevel      slog.Level
+	logErrorLevel slog.Level
+	logBodies     bool
 }
 
 // Option configures a Client.
@@ -50,56 +61,148 @@ func NewClientFromConfig(cfg Config, opts ...Option) *Client {
 		httpClient: cfg.HTTPClient,
 		apiKey:     cfg.APIKey,
 		userAgent:  cfg.UserAgent,
+
+		logLevel:      slog.LevelInfo,
+		logErrorLevel: slog.LevelWarn,
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.doer = c.chain()
 	return c
 }
 
-// do performs an HTTP request and decodes the JSON response into out if non-nil.
-func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
-	fullURL, err := url.JoinPath(c.baseURL, path)
+// do runs the operation named op through the middleware chain and decodes the
+// JSON response into out if non-nil.
+func (c *Client) do(ctx context.Context, op, method, path string, query url.Values, body any, out any, opts []CallOption) error {
+	o := NewCallOptions(opts...)
+	call := &Call{Operation: op, Method: method, Path: path, Query: query, Body: body, Out: out, opts: o}
+	if o.Header != nil {
+		call.Header = o.Header.Clone()
+	}
+	key := o.IdempotencyKey
+	if key == "" && op == OpLaunchAgent && c.retry != nil && !o.NoRetries && call.Header.Get(HeaderIdempotencyKey) == "" {
+		key = newIdempotencyKey()
+	}
+	if key != "" {
+		if call.Header == nil {
+			call.Header = http.Header{}
+		}
+		call.Header.Set(HeaderIdempotencyKey, key)
+	}
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	d := c.doer
+	if d == nil {
+		d = DoerFunc(c.execute)
+	}
+	err := d.Do(ctx, call)
+	if o.Meta != nil {
+		*o.Meta = ResponseMeta{}
+		if call.Response != nil {
+			*o.Meta = *call.Response
+		}
+	}
+	return err
+}
+
+// execute performs a call over HTTP. When a RetryPolicy is configured and the
+// call does not disable retries, failed attempts are retried according to it.
+// When a RateLimiter is configured, every attempt takes a token for the call's
+// path first.
+func (c *Client) execute(ctx context.Context, call *Call) error {
+	baseURL := c.baseURL
+	if call.opts.BaseURL != "" {
+		baseURL = call.opts.BaseURL
+	}
+	fullURL, err := url.JoinPath(baseURL, call.Path)
 	if err != nil {
 		return err
 	}
-	if query != nil {
+	if call.Query != nil {
 		u, err := url.Parse(fullURL)
 		if err != nil {
 			return err
 		}
-		u.RawQuery = query.Encode()
+		u.RawQuery = call.Query.Encode()
 		fullURL = u.String()
 	}
 
-	var reqBody io.Reader
-	if body != nil {
-		b, err := json.Marshal(body)
+	var payload []byte
+	if call.Body != nil {
+		payload, err = json.Marshal(call.Body)
 		if err != nil {
 			return err
 		}
-		reqBody = bytes.NewReader(b)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	start := time.Now()
+	defer func() {
+		if call.Response != nil {
+			call.Response.Latency = time.Since(start)
+			call.Response.Attempts = call.Attempts
+		}
+	}()
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, call.Path); err != nil {
+				return err
+			}
+		}
+		call.Attempts++
+		err := c.send(ctx, call, fullURL, payload)
+		if err == nil {
+			return nil
+		}
+		delay, ok := c.retryDelay(ctx, call, attempt, err)
+		if !ok {
+			return err
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// send performs a single HTTP attempt.
+func (c *Client) send(ctx context.Context, call *Call, fullURL string, payload []byte) error {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, call.Method, fullURL, reqBody)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	apiKey := c.apiKey
+	if call.opts.APIKey != "" {
+		apiKey = call.opts.APIKey
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Accept", "application/json")
-	if body != nil {
+	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	if c.userAgent != "" {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
+	for k, v := range call.Header {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
 
+	call.StatusCode = 0
+	call.Response = nil
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-
+	call.StatusCode = resp.StatusCode
+	call.Response = newResponseMeta(resp)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
 		var parsed struct {
@@ -114,13 +217,15 @@ func (c *Client) do(ctx context.Context, method, path string, query url.Values,
 			Message:    parsed.Error.Message,
 			Code:       parsed.Error.Code,
 			Body:       string(b),
+			Meta:       call.Response,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
 	}
 
-	if out == nil {
+	if call.Out == nil {
 		io.Copy(io.Discard, resp.Body)
 		return nil
 	}
 	dec := json.NewDecoder(resp.Body)
-	return dec.Decode(out)
+	return dec.Decode(call.Out)
 }
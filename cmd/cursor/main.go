@@ -0,0 +1,448 @@
+// Command cursor is a command-line client for the Cursor Background Agents API.
+//
+// Usage:
+//
+//	cursor [--output table|json|yaml] <command> [flags]
+//
+// Commands:
+//
+//	agents launch --repo URL --prompt TEXT [--ref REF] [--branch NAME] [--auto-pr] [--model NAME] [--webhook-url URL] [--webhook-secret SECRET] [--wait]
+//	agents list [--limit N] [--cursor C] [--all]
+//	agents get ID
+//	agents delete ID
+//	agents followup ID --prompt TEXT
+//	agents wait ID [--timeout DURATION]
+//	agents conversation ID
+//	agents tail ID [--interval DURATION] [--new]
+//	models
+//	repos
+//	me
+//
+// Configuration is read from the environment (see cursor.ConfigFromEnv).
+// The exit code reflects the kind of failure; see the exit* constants.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// Exit codes.
+const (
+	exitOK          = 0
+	exitError       = 1 // unclassified failure
+	exitUsage       = 2 // invalid command line
+	exitAuth        = 3 // missing or rejected API key (401, 403)
+	exitNotFound    = 4 // 404
+	exitRateLimited = 5 // 429 or client-side rate limit
+	exitServer      = 6 // 5xx
+	exitAgentFailed = 7 // agent ended with ERROR or EXPIRED
+	exitConflict    = 8 // 409
+)
+
+const usage = `Usage: cursor [--output table|json|yaml] <command> [flags]
+
+Commands:
+  agents launch --repo URL --prompt TEXT [flags]   launch a background agent
+  agents list [--limit N] [--cursor C] [--all]    list agents
+  agents get ID                                   show an agent
+  agents delete ID                                delete an agent
+  agents followup ID --prompt TEXT                add a follow-up instruction
+  agents wait ID [--timeout DURATION]             wait for an agent to finish
+  agents conversation ID                          show an agent's conversation
+  agents tail ID [--interval D] [--new]           follow an agent's conversation
+  models                                          list available models
+  repos                                           list GitHub repositories
+  me                                              show API key information
+
+Configuration is read from CURSOR_API_KEY, CURSOR_BASE_URL, CURSOR_USER_AGENT
+and CURSOR_TIMEOUT_SECONDS.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// usageError marks errors caused by invalid command-line input.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// app holds state shared by all commands.
+type app struct {
+	client *cursor.Client
+	format *string
+	out    *printer
+}
+
+// command runs a single CLI command with its remaining arguments.
+type command func(ctx context.Context, a *app, args []string) error
+
+var agentCommands = map[string]command{
+	"launch":       cmdLaunch,
+	"list":         cmdList,
+	"get":          cmdGet,
+	"delete":       cmdDelete,
+	"followup":     cmdFollowup,
+	"wait":         cmdWait,
+	"conversation": cmdConversation,
+	"tail":         cmdTail,
+}
+
+var errMissingKey = errors.New("CURSOR_API_KEY must be set")
+
+// run executes the CLI and returns the process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("cursor", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	format := global.String("output", "table", "output format: table, json or yaml")
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	rest := global.Args()
+	if len(rest) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	err := checkFormat(*format)
+	if err == nil {
+		err = dispatch(ctx, rest, format, stdout)
+	}
+	if err == nil {
+		return exitOK
+	}
+	fmt.Fprintln(stderr, "cursor:", err)
+	var apiErr *cursor.APIError
+	if errors.As(err, &apiErr) && apiErr.Meta != nil && apiErr.Meta.RequestID != "" {
+		fmt.Fprintln(stderr, "cursor: request ID:", apiErr.Meta.RequestID)
+	}
+	var uerr *usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprint(stderr, usage)
+	}
+	return exitCode(err)
+}
+
+func dispatch(ctx context.Context, args []string, format *string, stdout io.Writer) error {
+	var cmd command
+	switch name := args[0]; name {
+	case "agents":
+		if len(args) < 2 {
+			return usagef("agents: missing subcommand")
+		}
+		var ok bool
+		if cmd, ok = agentCommands[args[1]]; !ok {
+			return usagef("agents: unknown subcommand %q", args[1])
+		}
+		args = args[2:]
+	case "models":
+		cmd, args = cmdModels, args[1:]
+	case "repos":
+		cmd, args = cmdRepos, args[1:]
+	case "me":
+		cmd, args = cmdMe, args[1:]
+	default:
+		return usagef("unknown command %q", name)
+	}
+
+	cfg, err := cursor.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	if cfg.APIKey == "" {
+		return errMissingKey
+	}
+	a := &app{
+		client: cursor.NewClientFromConfig(cfg, cursor.WithRetry(cursor.DefaultRetryPolicy())),
+		format: format,
+		out:    &printer{format: format, w: stdout},
+	}
+	return cmd(ctx, a, args)
+}
+
+// exitCode maps an error to a process exit code.
+func exitCode(err error) int {
+	var uerr *usageError
+	var failed *cursor.AgentFailedError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &uerr):
+		return exitUsage
+	case errors.Is(err, errMissingKey), errors.Is(err, cursor.ErrUnauthorized), errors.Is(err, cursor.ErrForbidden):
+		return exitAuth
+	case errors.Is(err, cursor.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, cursor.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, cursor.ErrConflict):
+		return exitConflict
+	case errors.Is(err, cursor.ErrServer):
+		return exitServer
+	case errors.As(err, &failed):
+		return exitAgentFailed
+	}
+	return exitError
+}
+
+// newFlags creates a subcommand flag set that also accepts --output.
+func (a *app) newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(a.format, "output", *a.format, "output format: table, json or yaml")
+	return fs
+}
+
+// parse parses subcommand flags, allowing positional arguments before flags,
+// and checks the number of positional arguments.
+func parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usagef("%s: %v", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+	if len(pos) != positional {
+		return nil, usagef("%s: expected %d argument(s), got %d", fs.Name(), positional, len(pos))
+	}
+	if f := fs.Lookup("output"); f != nil {
+		if err := checkFormat(f.Value.String()); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+// interrupted reports whether err was caused by canceling ctx, which happens
+// when the user presses Ctrl-C.
+func interrupted(ctx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled)
+}
+
+func cmdLaunch(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlags("agents launch")
+	repo := fs.String("repo", "", "GitHub repository URL (required)")
+	prompt := fs.String("prompt", "", "instructions for the agent (required)")
+	ref := fs.String("ref", "", "git ref to start from")
+	branch := fs.String("branch", "", "branch name for the agent's changes")
+	autoPR := fs.Bool("auto-pr", false, "open a pull request when the agent finishes")
+	model := fs.String("model", "", "model name")
+	webhookURL := fs.String("webhook-url", "", "webhook URL for status changes")
+	webhookSecret := fs.String("webhook-secret", "", "webhook signing secret")
+	wait := fs.Bool("wait", false, "wait for the agent to finish")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *repo == "" || *prompt == "" {
+		return usagef("agents launch: --repo and --prompt are required")
+	}
+
+	req := cursor.LaunchRequest{
+		Prompt: cursor.Prompt{Text: *prompt},
+		Source: cursor.Source{Repository: *repo, Ref: *ref},
+		Model:  *model,
+	}
+	if *branch != "" || *autoPR {
+		req.Target = &cursor.LaunchTarget{BranchName: *branch, AutoCreatePR: *autoPR}
+	}
+	if *webhookURL != "" {
+		req.Webhook = &cursor.LaunchWebhook{URL: *webhookURL, Secret: *webhookSecret}
+	}
+	agent, err := a.client.LaunchAgent(ctx, req)
+	if err != nil {
+		return err
+	}
+	if *wait {
+		agent, err = a.client.WaitForAgent(ctx, agent.ID, nil)
+		if agent != nil {
+			if perr := a.out.agent(agent); perr != nil {
+				return perr
+			}
+		}
+		if interrupted(ctx, err) {
+			return nil
+		}
+		return err
+	}
+	return a.out.agent(agent)
+}
+
+func cmdList(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlags("agents list")
+	limit := fs.Int("limit", 20, "page size")
+	cur := fs.String("cursor", "", "pagination cursor")
+	all := fs.Bool("all", false, "fetch every page")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *all {
+		agents, err := a.client.AllAgents(ctx, &cursor.ListAgentsOptions{PageSize: *limit, Cursor: *cur})
+		if err != nil {
+			return err
+		}
+		return a.out.agents(&cursor.ListAgentsResponse{Agents: agents})
+	}
+	resp, err := a.client.ListAgents(ctx, *limit, cur)
+	if err != nil {
+		return err
+	}
+	return a.out.agents(resp)
+}
+
+func cmdGet(ctx context.Context, a *app, args []string) error {
+	pos, err := parse(a.newFlags("agents get"), args, 1)
+	if err != nil {
+		return err
+	}
+	agent, err := a.client.GetAgent(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return a.out.agent(agent)
+}
+
+func cmdDelete(ctx context.Context, a *app, args []string) error {
+	pos, err := parse(a.newFlags("agents delete"), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := a.client.DeleteAgent(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return a.out.id(id)
+}
+
+func cmdFollowup(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlags("agents followup")
+	prompt := fs.String("prompt", "", "follow-up instructions (required)")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *prompt == "" {
+		return usagef("agents followup: --prompt is required")
+	}
+	id, err := a.client.AddFollowup(ctx, pos[0], cursor.FollowupRequest{Prompt: cursor.Prompt{Text: *prompt}})
+	if err != nil {
+		return err
+	}
+	return a.out.id(id)
+}
+
+func cmdWait(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlags("agents wait")
+	timeout := fs.Duration("timeout", 0, "maximum time to wait (0 waits indefinitely)")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	agent, err := a.client.WaitForAgent(ctx, pos[0], &cursor.WaitOptions{MaxInterval: 15 * time.Second})
+	if agent != nil {
+		if perr := a.out.agent(agent); perr != nil {
+			return perr
+		}
+	}
+	if interrupted(ctx, err) {
+		return nil
+	}
+	return err
+}
+
+func cmdConversation(ctx context.Context, a *app, args []string) error {
+	pos, err := parse(a.newFlags("agents conversation"), args, 1)
+	if err != nil {
+		return err
+	}
+	conv, err := a.client.GetConversation(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return a.out.conversation(conv)
+}
+
+func cmdTail(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlags("agents tail")
+	interval := fs.Duration("interval", 2*time.Second, "delay between polls")
+	onlyNew := fs.Bool("new", false, "skip messages sent before tailing started")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	for m, err := range a.client.TailConversation(ctx, pos[0], &cursor.TailOptions{Interval: *interval, SkipExisting: *onlyNew}) {
+		if interrupted(ctx, err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := a.out.message(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cmdModels(ctx context.Context, a *app, args []string) error {
+	if _, err := parse(a.newFlags("models"), args, 0); err != nil {
+		return err
+	}
+	resp, err := a.client.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.models(resp)
+}
+
+func cmdRepos(ctx context.Context, a *app, args []string) error {
+	if _, err := parse(a.newFlags("repos"), args, 0); err != nil {
+		return err
+	}
+	resp, err := a.client.ListRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.repos(resp)
+}
+
+func cmdMe(ctx context.Context, a *app, args []string) error {
+	if _, err := parse(a.newFlags("me"), args, 0); err != nil {
+		return err
+	}
+	resp, err := a.client.Me(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.me(resp)
+}
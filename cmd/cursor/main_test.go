@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLI(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithAPIKey("cli-key"))
+	defer srv.Close()
+	t.Setenv("CURSOR_API_KEY", "cli-key")
+	t.Setenv("CURSOR_BASE_URL", srv.URL)
+
+	code, out, _ := runCLI(t, "--output", "json", "agents", "launch", "--repo", "https://github.com/octocat/hello-world", "--prompt", "Add a README")
+	require.Equal(t, exitOK, code)
+	var agent cursor.Agent
+	require.NoError(t, json.Unmarshal([]byte(out), &agent))
+	require.NotEmpty(t, agent.ID)
+
+	code, out, _ = runCLI(t, "agents", "get", agent.ID, "--output", "yaml")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "id: "+agent.ID)
+
+	code, out, _ = runCLI(t, "agents", "list")
+	require.Equal(t, exitOK, code)
+	require.True(t, strings.HasPrefix(out, "ID"))
+	require.Contains(t, out, agent.ID)
+
+	code, out, _ = runCLI(t, "agents", "tail", agent.ID, "--interval", "1ms")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "[assistant_message] Completed the requested changes.")
+
+	code, out, _ = runCLI(t, "models")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "MODEL")
+
+	code, _, stderr := runCLI(t, "agents", "get", "missing")
+	require.Equal(t, exitNotFound, code)
+	require.Contains(t, stderr, "cursor: request ID: req_")
+
+	code, _, stderr = runCLI(t, "agents", "frobnicate")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "Usage:")
+
+	// An invalid format is rejected before any request is made.
+	for _, args := range [][]string{
+		{"--output", "xml", "agents", "launch", "--repo", "https://github.com/octocat/hello-world", "--prompt", "Again"},
+		{"agents", "launch", "--repo", "https://github.com/octocat/hello-world", "--prompt", "Again", "--output", "xml"},
+	} {
+		code, _, stderr = runCLI(t, args...)
+		require.Equal(t, exitUsage, code)
+		require.Contains(t, stderr, `unknown output format "xml"`)
+	}
+	list, err := srv.Client().ListAgents(context.Background(), 0, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Agents, 1)
+
+	// Ctrl-C ends tail and wait cleanly.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, args := range [][]string{{"agents", "tail", agent.ID}, {"agents", "wait", agent.ID}} {
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, exitOK, run(ctx, args, &stdout, &stderr), stderr.String())
+	}
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusForbidden})
+	code, _, _ = runCLI(t, "me")
+	require.Equal(t, exitAuth, code)
+
+	t.Setenv("CURSOR_API_KEY", "")
+	code, _, _ = runCLI(t, "models")
+	require.Equal(t, exitAuth, code)
+}
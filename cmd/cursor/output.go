@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// checkFormat returns a usage error if format is not a supported output format.
+func checkFormat(format string) error {
+	switch format {
+	case "table", "json", "yaml":
+		return nil
+	}
+	return usagef("unknown output format %q", format)
+}
+
+// printer renders command results in the selected output format.
+type printer struct {
+	format *string
+	w      io.Writer
+}
+
+// structured writes v as JSON or YAML and reports whether the format was structured.
+func (p *printer) structured(v any) (bool, error) {
+	switch *p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return true, enc.Encode(v)
+	case "yaml":
+		// Round-trip through JSON so YAML keys match the API field names.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return true, err
+		}
+		var generic any
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return true, err
+		}
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return true, err
+		}
+		return true, enc.Close()
+	case "table":
+		return false, nil
+	}
+	return true, usagef("unknown output format %q", *p.format)
+}
+
+// table writes rows as aligned columns under header.
+func (p *printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) agent(a *cursor.Agent) error {
+	if ok, err := p.structured(a); ok {
+		return err
+	}
+	rows := [][]string{
+		{"ID", a.ID},
+		{"NAME", a.Name},
+		{"STATUS", string(a.Status)},
+		{"REPOSITORY", a.Source.Repository},
+		{"REF", a.Source.Ref},
+		{"BRANCH", a.Target.BranchName},
+		{"URL", a.Target.URL},
+		{"PR", deref(a.Target.PRURL)},
+		{"CREATED", formatTime(a.CreatedAt)},
+		{"SUMMARY", deref(a.Summary)},
+	}
+	return p.table([]string{"FIELD", "VALUE"}, rows)
+}
+
+func (p *printer) agents(resp *cursor.ListAgentsResponse) error {
+	if ok, err := p.structured(resp); ok {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Agents))
+	for _, a := range resp.Agents {
+		rows = append(rows, []string{a.ID, string(a.Status), a.Name, a.Source.Repository, formatTime(a.CreatedAt)})
+	}
+	if err := p.table([]string{"ID", "STATUS", "NAME", "REPOSITORY", "CREATED"}, rows); err != nil {
+		return err
+	}
+	if resp.NextCursor != nil && *resp.NextCursor != "" {
+		_, err := fmt.Fprintf(p.w, "\nnext cursor: %s\n", *resp.NextCursor)
+		return err
+	}
+	return nil
+}
+
+func (p *printer) conversation(c *cursor.Conversation) error {
+	if ok, err := p.structured(c); ok {
+		return err
+	}
+	rows := make([][]string, 0, len(c.Messages))
+	for _, m := range c.Messages {
+		rows = append(rows, []string{m.Type, strings.ReplaceAll(m.Text, "\n", " ")})
+	}
+	return p.table([]string{"TYPE", "TEXT"}, rows)
+}
+
+// message writes a single message as it arrives: one line per message in table
+// format, one document per message in JSON and YAML.
+func (p *printer) message(m cursor.Message) error {
+	switch *p.format {
+	case "json":
+		return json.NewEncoder(p.w).Encode(m)
+	case "yaml":
+		if _, err := fmt.Fprintln(p.w, "---"); err != nil {
+			return err
+		}
+		_, err := p.structured(m)
+		return err
+	case "table":
+		_, err := fmt.Fprintf(p.w, "[%s] %s\n", m.Type, m.Text)
+		return err
+	}
+	return usagef("unknown output format %q", *p.format)
+}
+
+func (p *printer) models(resp *cursor.ListModelsResponse) error {
+	if ok, err := p.structured(resp); ok {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		rows = append(rows, []string{m})
+	}
+	return p.table([]string{"MODEL"}, rows)
+}
+
+func (p *printer) repos(resp *cursor.ListRepositoriesResponse) error {
+	if ok, err := p.structured(resp); ok {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Repositories))
+	for _, r := range resp.Repositories {
+		rows = append(rows, []string{r.Repository, r.Owner, r.Name})
+	}
+	return p.table([]string{"REPOSITORY", "OWNER", "NAME"}, rows)
+}
+
+func (p *printer) me(resp *cursor.MeResponse) error {
+	if ok, err := p.structured(resp); ok {
+		return err
+	}
+	return p.table([]string{"FIELD", "VALUE"}, [][]string{
+		{"API KEY", resp.APIKeyName},
+		{"EMAIL", resp.UserEmail},
+		{"CREATED", formatTime(resp.CreatedAt)},
+	})
+}
+
+func (p *printer) id(id string) error {
+	if ok, err := p.structured(map[string]string{"id": id}); ok {
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, id)
+	return err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
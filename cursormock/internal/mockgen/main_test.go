@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedFileIsUpToDate(t *testing.T) {
+	want, err := generate("../../../interfaces.go")
+	require.NoError(t, err)
+	got, err := os.ReadFile("../../client_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "run go generate ./cursormock")
+}
@@ -0,0 +1,66 @@
+// Package cursormock provides a configurable mock of cursor.API.
+//
+// Set the *Func field of each method a test needs; calling a method whose
+// function is not set returns ErrNotConfigured. Every call is recorded and can
+// be inspected with Calls, together with the settings of its call options.
+//
+// The Client type and its methods are generated from cursor.API by
+// internal/mockgen; run go generate after changing the API interfaces.
+package cursormock
+
+//go:generate go run ./internal/mockgen
+
+import (
+	"errors"
+	"fmt"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// ErrNotConfigured is returned by methods whose function field is nil.
+var ErrNotConfigured = errors.New("cursormock: method not configured")
+
+// Call records a single invocation of a mocked method.
+type Call struct {
+	Method string
+	// Args holds the arguments between ctx and the call options.
+	Args []any
+	// Opts holds the settings of the call options, see cursor.NewCallOptions.
+	Opts cursor.CallOptions
+}
+
+// Calls returns the recorded calls, optionally filtered by method name.
+func (m *Client) Calls(method ...string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(method) == 0 {
+		return append([]Call(nil), m.calls...)
+	}
+	var out []Call
+	for _, c := range m.calls {
+		for _, name := range method {
+			if c.Method == name {
+				out = append(out, c)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Reset clears the recorded calls.
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Client) record(method string, opts []cursor.CallOption, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args, Opts: cursor.NewCallOptions(opts...)})
+}
+
+func notConfigured(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotConfigured, method)
+}
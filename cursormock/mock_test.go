@@ -0,0 +1,69 @@
+package cursormock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursormock"
+)
+
+// countingModels overrides a single method of the decorated API.
+type countingModels struct {
+	cursor.Decorator
+	n int
+}
+
+func (c *countingModels) ListModels(ctx context.Context, opts ...cursor.CallOption) (*cursor.ListModelsResponse, error) {
+	c.n++
+	return c.Decorator.ListModels(ctx, opts...)
+}
+
+func TestMockAndDecorator(t *testing.T) {
+	ctx := context.Background()
+	m := &cursormock.Client{
+		GetAgentFunc: func(ctx context.Context, id string) (*cursor.Agent, error) {
+			return &cursor.Agent{ID: id, Status: cursor.AgentStatusRunning}, nil
+		},
+		ListModelsFunc: func(ctx context.Context) (*cursor.ListModelsResponse, error) {
+			return &cursor.ListModelsResponse{Models: []string{"gpt-5"}}, nil
+		},
+	}
+
+	var api cursor.API = &countingModels{Decorator: cursor.Decorator{Next: m}}
+
+	agent, err := api.GetAgent(ctx, "bc_1")
+	require.NoError(t, err)
+	require.Equal(t, "bc_1", agent.ID)
+
+	models, err := api.ListModels(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpt-5"}, models.Models)
+	require.Equal(t, 1, api.(*countingModels).n)
+
+	_, err = api.Me(ctx)
+	require.ErrorIs(t, err, cursormock.ErrNotConfigured)
+
+	require.Len(t, m.Calls(), 3)
+	require.Equal(t, []cursormock.Call{{Method: "GetAgent", Args: []any{"bc_1"}}}, m.Calls("GetAgent"))
+}
+
+func TestMockRecordsCallOptions(t *testing.T) {
+	m := &cursormock.Client{}
+	_, err := m.LaunchAgent(context.Background(), cursor.LaunchRequest{Model: "gpt-5"},
+		cursor.WithIdempotencyKey("launch-1"), cursor.WithCallTimeout(time.Second), cursor.WithCallHeader("X-Trace", "a"))
+	require.ErrorIs(t, err, cursormock.ErrNotConfigured)
+
+	calls := m.Calls("LaunchAgent")
+	require.Len(t, calls, 1)
+	require.Equal(t, []any{cursor.LaunchRequest{Model: "gpt-5"}}, calls[0].Args)
+	require.Equal(t, "launch-1", calls[0].Opts.IdempotencyKey)
+	require.Equal(t, time.Second, calls[0].Opts.Timeout)
+	require.Equal(t, "a", calls[0].Opts.Header.Get("X-Trace"))
+
+	m.Reset()
+	require.Empty(t, m.Calls())
+}
@@ -0,0 +1,167 @@
+package cursortest_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+// requestLog records the headers of every request sent by a client.
+type requestLog struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (l *requestLog) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	l.headers = append(l.headers, req.Header.Clone())
+	l.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (l *requestLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.headers = nil
+}
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestCallOptions(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithAPIKey("key-a"))
+	defer srv.Close()
+	other := cursortest.NewServer(cursortest.WithAPIKey("key-b"), cursortest.WithModels("other-model"))
+	defer other.Close()
+
+	log := &requestLog{}
+	c := srv.Client(
+		cursor.WithHTTPClient(&http.Client{Transport: log}),
+		cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+	)
+	ctx := context.Background()
+
+	_, err := c.Me(ctx, cursor.WithCallHeader("X-Trace", "a"), cursor.WithCallHeader("X-Trace", "b"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, log.headers[0].Values("X-Trace"))
+
+	// Base URL and API key overrides apply to this call only.
+	models, err := c.ListModels(ctx, cursor.WithCallBaseURL(other.URL), cursor.WithCallAPIKey("key-b"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"other-model"}, models.Models)
+	_, err = c.ListModels(ctx, cursor.WithCallAPIKey("wrong"))
+	require.ErrorIs(t, err, cursor.ErrUnauthorized)
+	_, err = c.ListModels(ctx)
+	require.NoError(t, err)
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusBadGateway, Times: 1})
+	var meta cursor.ResponseMeta
+	_, err = c.Me(ctx, cursor.WithoutRetries(), cursor.WithResponseMeta(&meta))
+	require.ErrorIs(t, err, cursor.ErrServer)
+	require.Equal(t, 1, meta.Attempts)
+
+	srv.SetLatency(200 * time.Millisecond)
+	_, err = c.Me(ctx, cursor.WithCallTimeout(20*time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	log := &requestLog{}
+	c := srv.Client(
+		cursor.WithHTTPClient(&http.Client{Transport: log}),
+		cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+	)
+	ctx := context.Background()
+	req := cursor.LaunchRequest{
+		Prompt: cursor.Prompt{Text: "Add a README"},
+		Source: cursor.Source{Repository: "https://github.com/octocat/hello-world"},
+	}
+
+	// The launch succeeds on the server but the response is lost; the retry
+	// reuses the generated key, so no duplicate agent is created.
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents", StatusCode: http.StatusBadGateway, Times: 1, AfterHandling: true})
+	agent, err := c.LaunchAgent(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, log.headers, 2)
+	key := log.headers[0].Get(cursor.HeaderIdempotencyKey)
+	require.Regexp(t, uuidV4, key)
+	require.Equal(t, key, log.headers[1].Get(cursor.HeaderIdempotencyKey))
+	list, err := c.ListAgents(ctx, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Agents, 1)
+	require.Equal(t, agent.ID, list.Agents[0].ID)
+
+	// Each launch gets its own key, and only launches get one.
+	log.reset()
+	_, err = c.LaunchAgent(ctx, req)
+	require.NoError(t, err)
+	_, err = c.GetAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Regexp(t, uuidV4, log.headers[0].Get(cursor.HeaderIdempotencyKey))
+	require.NotEqual(t, key, log.headers[0].Get(cursor.HeaderIdempotencyKey))
+	require.Empty(t, log.headers[1].Get(cursor.HeaderIdempotencyKey))
+
+	// An explicit key is sent as is and deduplicates launches.
+	log.reset()
+	first, err := c.LaunchAgent(ctx, req, cursor.WithIdempotencyKey("launch-1"))
+	require.NoError(t, err)
+	second, err := c.LaunchAgent(ctx, req, cursor.WithIdempotencyKey("launch-1"))
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID)
+	require.Equal(t, "launch-1", log.headers[0].Get(cursor.HeaderIdempotencyKey))
+
+	// Without retries no key is generated, and a keyed POST is retried on 5xx.
+	log.reset()
+	_, err = srv.Client(cursor.WithHTTPClient(&http.Client{Transport: log})).LaunchAgent(ctx, req)
+	require.NoError(t, err)
+	_, err = c.LaunchAgent(ctx, req, cursor.WithoutRetries())
+	require.NoError(t, err)
+	require.Empty(t, log.headers[0].Get(cursor.HeaderIdempotencyKey))
+	require.Empty(t, log.headers[1].Get(cursor.HeaderIdempotencyKey))
+
+	srv.InjectFault(cursortest.Fault{Method: "POST", Path: "/v0/agents/*/followup", StatusCode: http.StatusInternalServerError, Times: 1})
+	var meta cursor.ResponseMeta
+	_, err = c.AddFollowup(ctx, agent.ID, cursor.FollowupRequest{Prompt: cursor.Prompt{Text: "More"}},
+		cursor.WithIdempotencyKey("followup-1"), cursor.WithResponseMeta(&meta))
+	require.NoError(t, err)
+	require.Equal(t, 2, meta.Attempts)
+}
+
+func TestHelperCallOptions(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithAPIKey("key-a"), cursortest.WithScript(
+		cursortest.Step{Status: cursor.AgentStatusFinished},
+	))
+	defer srv.Close()
+	agent := launch(t, srv.Client(), cursor.LaunchRequest{})
+
+	// The client's own key is wrong; the helpers pass the override to every request.
+	c := cursor.New("wrong", cursor.WithBaseURL(srv.URL))
+	ctx := context.Background()
+	key := cursor.WithCallAPIKey("key-a")
+
+	_, err := c.WaitForAgent(ctx, agent.ID, nil)
+	require.ErrorIs(t, err, cursor.ErrUnauthorized)
+	final, err := c.WaitForAgent(ctx, agent.ID, &cursor.WaitOptions{InitialInterval: time.Millisecond}, key)
+	require.NoError(t, err)
+	require.Equal(t, cursor.AgentStatusFinished, final.Status)
+
+	agents, err := c.AllAgents(ctx, nil, key)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+
+	var messages int
+	for _, err := range c.TailConversation(ctx, agent.ID, nil, key) {
+		require.NoError(t, err)
+		messages++
+	}
+	require.NotZero(t, messages)
+}
@@ -0,0 +1,245 @@
+package cursortest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// CassetteMode selects whether a Cassette records live traffic or replays it.
+type CassetteMode int
+
+const (
+	// ModeRecord forwards requests to the real transport and appends every exchange to the cassette file.
+	ModeRecord CassetteMode = iota
+	// ModeReplay serves responses from the cassette file without touching the network.
+	ModeReplay
+)
+
+// ErrNoInteraction is returned in replay mode when no recorded exchange matches a request.
+var ErrNoInteraction = errors.New("cursortest: no recorded interaction matches request")
+
+// redacted replaces sensitive header values in recorded cassettes.
+const redacted = "REDACTED"
+
+// Interaction is a single recorded request/response pair, stored as one JSON line.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the request half of an Interaction.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response half of an Interaction.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is an http.RoundTripper that records Client traffic to a JSONL file
+// or replays it from one. The Authorization header is never written to disk.
+//
+// In replay mode requests are matched by method, path, query and body (JSON bodies
+// are compared semantically). Each recorded exchange is served once, in order, so
+// repeated identical requests replay the responses they originally received.
+type Cassette struct {
+	mode CassetteMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	file         *os.File
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette opens a cassette at path. In ModeRecord the file is truncated and
+// requests are sent through next (http.DefaultTransport if nil). In ModeReplay the
+// file must exist.
+func NewCassette(path string, mode CassetteMode, next http.RoundTripper) (*Cassette, error) {
+	c := &Cassette{mode: mode, next: next}
+	if c.next == nil {
+		c.next = http.DefaultTransport
+	}
+	switch mode {
+	case ModeRecord:
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		c.file = f
+	case ModeReplay:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for s.Scan() {
+			if len(bytes.TrimSpace(s.Bytes())) == 0 {
+				continue
+			}
+			var in Interaction
+			if err := json.Unmarshal(s.Bytes(), &in); err != nil {
+				return nil, fmt.Errorf("cursortest: parse cassette %s: %w", path, err)
+			}
+			c.interactions = append(c.interactions, in)
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		c.used = make([]bool, len(c.interactions))
+	default:
+		return nil, fmt.Errorf("cursortest: unknown cassette mode %d", mode)
+	}
+	return c, nil
+}
+
+// HTTPClient returns an http.Client using the cassette as transport, suitable for cursor.WithHTTPClient.
+func (c *Cassette) HTTPClient() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Close flushes and closes the cassette file in record mode.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	rec := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Header: redactHeader(req.Header),
+		Body:   string(body),
+	}
+	if c.mode == ModeReplay {
+		return c.replay(req, rec)
+	}
+	return c.record(req, rec, body)
+}
+
+func (c *Cassette) record(req *http.Request, rec RecordedRequest, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := c.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	line, err := json.Marshal(Interaction{
+		Request: rec,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       string(respBody),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil, errors.New("cursortest: cassette is closed")
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, rec RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, in := range c.interactions {
+		if c.used[i] || !matches(in.Request, rec) {
+			continue
+		}
+		c.used[i] = true
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Response.Body))),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s?%s", ErrNoInteraction, rec.Method, rec.Path, rec.Query)
+}
+
+func matches(recorded, req RecordedRequest) bool {
+	return recorded.Method == req.Method &&
+		recorded.Path == req.Path &&
+		recorded.Query == req.Query &&
+		sameBody(recorded.Body, req.Body)
+}
+
+// sameBody compares bodies as JSON values when both parse, and byte-wise otherwise.
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if out.Get("Authorization") != "" {
+		out.Set("Authorization", redacted)
+	}
+	return out
+}
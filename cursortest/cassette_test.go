@@ -0,0 +1,61 @@
+package cursortest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	ctx := context.Background()
+
+	srv := cursortest.NewServer()
+	rec, err := cursortest.NewCassette(path, cursortest.ModeRecord, nil)
+	require.NoError(t, err)
+	live := cursor.New("live-key", cursor.WithBaseURL(srv.URL), cursor.WithHTTPClient(rec.HTTPClient()))
+
+	agent := launch(t, live, cursor.LaunchRequest{})
+	first, err := live.GetAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	second, err := live.GetAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	_, err = live.ListAgents(ctx, 5, nil)
+	require.NoError(t, err)
+	require.NoError(t, rec.Close())
+	srv.Close()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "live-key")
+
+	replay, err := cursortest.NewCassette(path, cursortest.ModeReplay, nil)
+	require.NoError(t, err)
+	offline := cursor.New("other-key", cursor.WithBaseURL(srv.URL), cursor.WithHTTPClient(replay.HTTPClient()))
+
+	got, err := offline.LaunchAgent(ctx, cursor.LaunchRequest{
+		Prompt: cursor.Prompt{Text: "Add a README"},
+		Source: cursor.Source{Repository: "https://github.com/octocat/hello-world"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agent.ID, got.ID)
+
+	g1, err := offline.GetAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Equal(t, first.Status, g1.Status)
+	g2, err := offline.GetAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.Status, g2.Status)
+
+	_, err = offline.ListAgents(ctx, 5, nil)
+	require.NoError(t, err)
+
+	_, err = offline.ListAgents(ctx, 6, nil)
+	require.ErrorIs(t, err, cursortest.ErrNoInteraction)
+}
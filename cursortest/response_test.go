@@ -0,0 +1,74 @@
+package cursortest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func TestResponseMeta(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	srv := cursortest.NewServer(
+		cursortest.WithHeader(cursor.HeaderRateLimitLimit, "60"),
+		cursortest.WithHeader(cursor.HeaderRateLimitRemaining, "59"),
+		cursortest.WithHeader(cursor.HeaderRateLimitReset, reset.Format(http.TimeFormat)),
+	)
+	defer srv.Close()
+	c := srv.Client(cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	ctx := context.Background()
+
+	var meta cursor.ResponseMeta
+	models, err := c.ListModels(ctx, cursor.WithResponseMeta(&meta))
+	require.NoError(t, err)
+	require.NotEmpty(t, models.Models)
+	require.Equal(t, http.StatusOK, meta.StatusCode)
+	require.Equal(t, "req_000001", meta.RequestID)
+	require.Equal(t, "application/json", meta.Header.Get("Content-Type"))
+	require.Equal(t, 1, meta.Attempts)
+	require.Positive(t, meta.Latency)
+	require.Equal(t, &cursor.RateLimitStatus{Limit: 60, Remaining: 59, Reset: reset.UTC()}, meta.RateLimit)
+
+	// Retries are counted and the metadata describes the last response.
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusBadGateway, Times: 1})
+	_, err = c.Me(ctx, cursor.WithResponseMeta(&meta))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, meta.StatusCode)
+	require.Equal(t, 2, meta.Attempts)
+	require.Equal(t, "req_000003", meta.RequestID)
+
+	// Failed calls fill both the option and APIError.Meta.
+	srv.InjectFault(cursortest.Fault{
+		Path:       "/v0/agents/*",
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{cursor.HeaderRateLimitRemaining: {"0"}, cursor.HeaderRateLimitReset: {"30"}},
+	})
+	_, err = c.GetAgent(ctx, "bc_1", cursor.WithResponseMeta(&meta))
+	require.ErrorIs(t, err, cursor.ErrRateLimited)
+	var apiErr *cursor.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.NotNil(t, apiErr.Meta)
+	require.Equal(t, meta, *apiErr.Meta)
+	require.Equal(t, http.StatusTooManyRequests, meta.StatusCode)
+	require.Equal(t, 3, meta.Attempts)
+	require.Equal(t, "req_000006", meta.RequestID)
+	require.Equal(t, 0, meta.RateLimit.Remaining)
+	require.WithinDuration(t, time.Now().Add(30*time.Second), meta.RateLimit.Reset, 5*time.Second)
+}
+
+func TestResponseMetaWithoutResponse(t *testing.T) {
+	srv := cursortest.NewServer()
+	c := srv.Client()
+	srv.Close()
+
+	meta := cursor.ResponseMeta{StatusCode: http.StatusOK, RequestID: "stale"}
+	_, err := c.Me(context.Background(), cursor.WithResponseMeta(&meta))
+	require.Error(t, err)
+	require.Equal(t, cursor.ResponseMeta{}, meta)
+}
@@ -0,0 +1,730 @@
+// Package cursortest provides an in-process fake of the Cursor API for tests.
+//
+// The fake is stateful: launched agents are stored, listed, followed up and deleted,
+// and their status advances according to a script every time they are observed.
+// Faults and latency can be injected, and status changes are delivered as signed
+// webhooks when an agent is launched with a webhook URL.
+package cursortest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// Step is a single stage of a scripted agent lifecycle.
+// The agent stays in Status until it has been observed via GetAgent Polls times
+// or After has elapsed, whichever condition is set and met first.
+// A step with neither condition advances on the next observation.
+// The last step of a script is final.
+type Step struct {
+	Status cursor.AgentStatus
+	Polls  int
+	After  time.Duration
+}
+
+// DefaultScript is the lifecycle applied to launched agents unless overridden:
+// CREATING for one poll, RUNNING for two polls, then FINISHED.
+var DefaultScript = []Step{
+	{Status: cursor.AgentStatusCreating, Polls: 1},
+	{Status: cursor.AgentStatusRunning, Polls: 2},
+	{Status: cursor.AgentStatusFinished},
+}
+
+// Fault describes an injected error response.
+type Fault struct {
+	// Method matches the request method; empty matches any method.
+	Method string
+	// Path is a path.Match pattern such as "/v0/agents/*"; empty matches any path.
+	Path string
+	// StatusCode is the HTTP status to respond with.
+	StatusCode int
+	// Code and Message populate the API error body.
+	Code    string
+	Message string
+	// RetryAfter, if positive, is sent as the Retry-After header in whole seconds.
+	RetryAfter time.Duration
+	// Times is the number of matching requests to fail; values <= 0 fail every matching request.
+	Times int
+	// Header holds extra headers sent with the error response.
+	Header http.Header
+	// AfterHandling processes the request before failing it, as when the
+	// server acted on a request but the response was lost.
+	AfterHandling bool
+}
+
+// WebhookDelivery records a webhook sent by the fake or a WebhookSimulator.
+type WebhookDelivery struct {
+	URL        string
+	Event      cursor.WebhookEvent
+	StatusCode int
+	Attempts   int
+	Err        error
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAPIKey makes the server require "Bearer <key>" authorization.
+func WithAPIKey(key string) Option {
+	return func(s *Server) { s.apiKey = key }
+}
+
+// WithScript sets the lifecycle applied to newly launched agents.
+func WithScript(steps ...Step) Option {
+	return func(s *Server) { s.script = steps }
+}
+
+// WithModels sets the models returned by GET /v0/models.
+func WithModels(models ...string) Option {
+	return func(s *Server) { s.models = models }
+}
+
+// WithRepositories sets the repositories returned by GET /v0/repositories.
+func WithRepositories(repos ...cursor.Repository) Option {
+	return func(s *Server) { s.repos = repos }
+}
+
+// WithMe sets the response of GET /v0/me.
+func WithMe(me cursor.MeResponse) Option {
+	return func(s *Server) { s.me = me }
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) { s.latency = d }
+}
+
+// WithHeader adds a header to every response, e.g. cursor.HeaderRateLimitRemaining.
+func WithHeader(key, value string) Option {
+	return func(s *Server) {
+		if s.header == nil {
+			s.header = http.Header{}
+		}
+		s.header.Add(key, value)
+	}
+}
+
+// Server is a stateful fake of the Cursor API backed by httptest.Server.
+type Server struct {
+	// URL is the base URL of the fake, suitable for cursor.WithBaseURL.
+	URL string
+
+	srv *httptest.Server
+
+	apiKey  string
+	script  []Step
+	models  []string
+	repos   []cursor.Repository
+	me      cursor.MeResponse
+	latency time.Duration
+	header  http.Header
+
+	mu       sync.Mutex
+	agents   map[string]*agentState
+	launches map[string]string // idempotency key -> agent ID
+	order    []string          // agent IDs in creation order
+	nextID   int
+	faults   []*Fault
+	requests []string
+
+	hookMu     sync.Mutex
+	hooks      []hook
+	deliveries []WebhookDelivery
+	wake       chan struct{}
+	pending    sync.WaitGroup
+	done       chan struct{}
+}
+
+type agentState struct {
+	agent     cursor.Agent
+	messages  []cursor.Message
+	script    []Step
+	step      int
+	polls     int
+	enteredAt time.Time
+	webhook   *cursor.LaunchWebhook
+}
+
+type hook struct {
+	webhook cursor.LaunchWebhook
+	event   cursor.WebhookEvent
+}
+
+// NewServer starts a fake Cursor API server. Call Close when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		script: DefaultScript,
+		models: []string{"claude-4-sonnet", "gpt-5", "o3"},
+		repos: []cursor.Repository{
+			{Owner: "octocat", Name: "hello-world", Repository: "https://github.com/octocat/hello-world"},
+		},
+		me: cursor.MeResponse{
+			APIKeyName: "cursortest",
+			CreatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			UserEmail:  "test@example.com",
+		},
+		agents:   make(map[string]*agentState),
+		launches: make(map[string]string),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v0/agents", s.handleLaunch)
+	mux.HandleFunc("GET /v0/agents", s.handleList)
+	mux.HandleFunc("GET /v0/agents/{id}", s.handleGet)
+	mux.HandleFunc("DELETE /v0/agents/{id}", s.handleDelete)
+	mux.HandleFunc("POST /v0/agents/{id}/followup", s.handleFollowup)
+	mux.HandleFunc("GET /v0/agents/{id}/conversation", s.handleConversation)
+	mux.HandleFunc("GET /v0/models", s.handleModels)
+	mux.HandleFunc("GET /v0/repositories", s.handleRepositories)
+	mux.HandleFunc("GET /v0/me", s.handleMe)
+
+	s.srv = httptest.NewServer(s.middleware(mux))
+	s.URL = s.srv.URL
+	go s.deliverHooks()
+	return s
+}
+
+// Close waits for pending webhooks and shuts the server down.
+func (s *Server) Close() {
+	s.FlushWebhooks()
+	close(s.done)
+	s.srv.Close()
+}
+
+// Client returns a cursor.Client pointed at the fake. Extra options are applied last.
+func (s *Server) Client(opts ...cursor.Option) *cursor.Client {
+	key := s.apiKey
+	if key == "" {
+		key = "cursortest-key"
+	}
+	base := []cursor.Option{cursor.WithBaseURL(s.URL), cursor.WithHTTPClient(s.srv.Client())}
+	return cursor.New(key, append(base, opts...)...)
+}
+
+// InjectFault makes matching requests fail. Faults are checked in the order they were added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// SetLatency delays every subsequent response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Requests returns the "METHOD /path" of every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// AddAgent seeds the fake with an existing agent. Its status does not change unless scripted.
+func (s *Server) AddAgent(a cursor.Agent, messages ...cursor.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	if _, ok := s.agents[a.ID]; !ok {
+		s.order = append(s.order, a.ID)
+	}
+	s.agents[a.ID] = &agentState{
+		agent:     a,
+		messages:  messages,
+		script:    []Step{{Status: a.Status}},
+		enteredAt: time.Now(),
+	}
+}
+
+// Agent returns a snapshot of the agent with the given ID without advancing its script.
+func (s *Server) Agent(id string) (cursor.Agent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.agents[id]
+	if !ok {
+		return cursor.Agent{}, false
+	}
+	return st.agent, true
+}
+
+// Script replaces the lifecycle of an agent, starting from the first step.
+func (s *Server) Script(id string, steps ...Step) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.agents[id]
+	if !ok {
+		return fmt.Errorf("cursortest: agent %s not found", id)
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("cursortest: empty script")
+	}
+	st.script = steps
+	st.step = 0
+	s.enterStep(st)
+	return nil
+}
+
+// SetStatus moves an agent to status immediately and makes it final.
+func (s *Server) SetStatus(id string, status cursor.AgentStatus) error {
+	return s.Script(id, Step{Status: status})
+}
+
+// AppendMessage adds a message to an agent's conversation.
+func (s *Server) AppendMessage(id string, m cursor.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.agents[id]
+	if !ok {
+		return fmt.Errorf("cursortest: agent %s not found", id)
+	}
+	if m.ID == "" {
+		m.ID = s.newID("msg")
+	}
+	st.messages = append(st.messages, m)
+	return nil
+}
+
+// Webhooks returns the webhook deliveries attempted so far.
+// Call FlushWebhooks first to wait for in-flight deliveries.
+func (s *Server) Webhooks() []WebhookDelivery {
+	s.hookMu.Lock()
+	defer s.hookMu.Unlock()
+	return append([]WebhookDelivery(nil), s.deliveries...)
+}
+
+// FlushWebhooks blocks until all queued webhooks have been delivered.
+func (s *Server) FlushWebhooks() {
+	s.pending.Wait()
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		requestID := fmt.Sprintf("req_%06d", len(s.requests))
+		latency := s.latency
+		fault := s.matchFault(r)
+		s.mu.Unlock()
+
+		w.Header().Set(cursor.HeaderRequestID, requestID)
+		for k, v := range s.header {
+			w.Header()[k] = v
+		}
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid API key")
+			return
+		}
+		if fault != nil {
+			if fault.AfterHandling {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+			}
+			for k, v := range fault.Header {
+				w.Header()[k] = v
+			}
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+			}
+			writeError(w, fault.StatusCode, fault.Code, fault.Message)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchFault returns the first fault matching r and consumes one use of it. Callers hold s.mu.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Path != "" {
+			if ok, _ := path.Match(f.Path, r.URL.Path); !ok {
+				continue
+			}
+		}
+		out := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &out
+	}
+	return nil
+}
+
+func (s *Server) handleLaunch(w http.ResponseWriter, r *http.Request) {
+	var req cursor.LaunchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+		return
+	}
+	if strings.TrimSpace(req.Prompt.Text) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "prompt.text is required")
+		return
+	}
+	if req.Source.Repository == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "source.repository is required")
+		return
+	}
+
+	key := r.Header.Get(cursor.HeaderIdempotencyKey)
+	s.mu.Lock()
+	if st, ok := s.agents[s.launches[key]]; ok && key != "" {
+		out := st.agent
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+	id := s.newID("bc")
+	st := &agentState{
+		agent: cursor.Agent{
+			ID:     id,
+			Name:   agentName(req.Prompt.Text),
+			Source: req.Source,
+			Target: cursor.Target{
+				BranchName: "cursor/" + id,
+				URL:        "https://cursor.com/agents?id=" + id,
+			},
+			CreatedAt: time.Now().UTC(),
+		},
+		messages: []cursor.Message{{ID: s.newID("msg"), Type: "user_message", Text: req.Prompt.Text}},
+		script:   s.script,
+		webhook:  req.Webhook,
+	}
+	if req.Target != nil {
+		st.agent.Target.AutoCreatePR = req.Target.AutoCreatePR
+		if req.Target.BranchName != "" {
+			st.agent.Target.BranchName = req.Target.BranchName
+		}
+	}
+	if len(st.script) == 0 {
+		st.script = DefaultScript
+	}
+	st.agent.Status = st.script[0].Status
+	st.enteredAt = time.Now()
+	s.agents[id] = st
+	s.order = append(s.order, id)
+	if key != "" {
+		s.launches[key] = id
+	}
+	out := st.agent
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	st, ok := s.agents[r.PathValue("id")]
+	if !ok {
+		s.mu.Unlock()
+		writeNotFound(w)
+		return
+	}
+	s.advance(st)
+	st.polls++
+	out := st.agent
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	offset := 0
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "invalid cursor")
+			return
+		}
+		offset = n
+	}
+
+	s.mu.Lock()
+	var resp cursor.ListAgentsResponse
+	resp.Agents = []cursor.Agent{}
+	// Newest first.
+	for i := len(s.order) - 1 - offset; i >= 0 && len(resp.Agents) < limit; i-- {
+		st := s.agents[s.order[i]]
+		s.advance(st)
+		resp.Agents = append(resp.Agents, st.agent)
+	}
+	if next := offset + len(resp.Agents); next < len(s.order) {
+		c := strconv.Itoa(next)
+		resp.NextCursor = &c
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	_, ok := s.agents[id]
+	if ok {
+		delete(s.agents, id)
+		for i, v := range s.order {
+			if v == id {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, cursor.DeleteResponse{ID: id})
+}
+
+func (s *Server) handleFollowup(w http.ResponseWriter, r *http.Request) {
+	var req cursor.FollowupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+		return
+	}
+	if strings.TrimSpace(req.Prompt.Text) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "prompt.text is required")
+		return
+	}
+	id := r.PathValue("id")
+	s.mu.Lock()
+	st, ok := s.agents[id]
+	if ok {
+		st.messages = append(st.messages, cursor.Message{ID: s.newID("msg"), Type: "user_message", Text: req.Prompt.Text})
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, cursor.FollowupResponse{ID: id})
+}
+
+func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	st, ok := s.agents[id]
+	var out cursor.Conversation
+	if ok {
+		s.advance(st)
+		out = cursor.Conversation{ID: id, Messages: append([]cursor.Message{}, st.messages...)}
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, cursor.ListModelsResponse{Models: s.models})
+}
+
+func (s *Server) handleRepositories(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, cursor.ListRepositoriesResponse{Repositories: s.repos})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.me)
+}
+
+// advance moves an agent through its script as far as the step conditions allow.
+// Callers hold s.mu.
+func (s *Server) advance(st *agentState) {
+	for st.step < len(st.script)-1 {
+		cur := st.script[st.step]
+		pollsMet := cur.Polls > 0 && st.polls >= cur.Polls
+		timeMet := cur.After > 0 && time.Since(st.enteredAt) >= cur.After
+		if cur.Polls > 0 || cur.After > 0 {
+			if !pollsMet && !timeMet {
+				return
+			}
+		}
+		st.step++
+		s.enterStep(st)
+	}
+}
+
+// enterStep applies the current step of the script. Callers hold s.mu.
+func (s *Server) enterStep(st *agentState) {
+	prev := st.agent.Status
+	st.agent.Status = st.script[st.step].Status
+	st.polls = 0
+	st.enteredAt = time.Now()
+	if st.agent.Status == prev {
+		return
+	}
+	if st.agent.Status == cursor.AgentStatusFinished {
+		summary := "Completed the requested changes."
+		st.agent.Summary = &summary
+		st.messages = append(st.messages, cursor.Message{ID: s.newID("msg"), Type: "assistant_message", Text: summary})
+	}
+	if st.webhook != nil && st.webhook.URL != "" {
+		s.enqueueHook(hook{webhook: *st.webhook, event: cursor.WebhookEvent{
+			Event:     "statusChange",
+			Timestamp: time.Now().UTC(),
+			ID:        st.agent.ID,
+			Status:    st.agent.Status,
+			Source:    st.agent.Source,
+			Target:    st.agent.Target,
+			Summary:   st.agent.Summary,
+		}})
+	}
+}
+
+// enqueueHook queues a webhook for delivery without blocking.
+func (s *Server) enqueueHook(h hook) {
+	s.pending.Add(1)
+	s.hookMu.Lock()
+	s.hooks = append(s.hooks, h)
+	s.hookMu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverHooks sends queued webhooks one at a time, preserving order.
+func (s *Server) deliverHooks() {
+	hc := &http.Client{Timeout: 10 * time.Second}
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+		for {
+			s.hookMu.Lock()
+			if len(s.hooks) == 0 {
+				s.hookMu.Unlock()
+				break
+			}
+			h := s.hooks[0]
+			s.hooks = s.hooks[1:]
+			s.hookMu.Unlock()
+
+			d := s.deliver(hc, h)
+			s.hookMu.Lock()
+			s.deliveries = append(s.deliveries, d)
+			s.hookMu.Unlock()
+			s.pending.Done()
+		}
+	}
+}
+
+// deliver POSTs a single signed webhook.
+func (s *Server) deliver(hc *http.Client, h hook) WebhookDelivery {
+	d := WebhookDelivery{URL: h.webhook.URL, Event: h.event, Attempts: 1}
+	body, err := json.Marshal(h.event)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	req, err := http.NewRequest(http.MethodPost, h.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Cursor-Agent-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", h.event.Event)
+	if h.webhook.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", cursor.Sign(h.webhook.Secret, body))
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	resp.Body.Close()
+	d.StatusCode = resp.StatusCode
+	return d
+}
+
+// newID returns a unique identifier with the given prefix. Callers hold s.mu.
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s_%06d", prefix, s.nextID)
+}
+
+func agentName(prompt string) string {
+	words := strings.Fields(prompt)
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	return strings.Join(words, " ")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Code    string `json:"code,omitempty"`
+		} `json:"error"`
+	}
+	body.Error.Message = message
+	body.Error.Code = code
+	writeJSON(w, status, body)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "not_found", "Agent not found")
+}
@@ -0,0 +1,291 @@
+package cursortest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func launch(t *testing.T, c *cursor.Client, req cursor.LaunchRequest) *cursor.Agent {
+	t.Helper()
+	if req.Prompt.Text == "" {
+		req.Prompt.Text = "Add a README"
+	}
+	if req.Source.Repository == "" {
+		req.Source.Repository = "https://github.com/octocat/hello-world"
+	}
+	agent, err := c.LaunchAgent(context.Background(), req)
+	require.NoError(t, err)
+	return agent
+}
+
+func TestEndpoints(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithAPIKey("secret-key"))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	me, err := c.Me(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "test@example.com", me.UserEmail)
+
+	models, err := c.ListModels(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, models.Models)
+
+	repos, err := c.ListRepositories(ctx)
+	require.NoError(t, err)
+	require.Len(t, repos.Repositories, 1)
+
+	agent := launch(t, c, cursor.LaunchRequest{Target: &cursor.LaunchTarget{BranchName: "feature/readme"}})
+	require.Equal(t, cursor.AgentStatusCreating, agent.Status)
+	require.Equal(t, "feature/readme", agent.Target.BranchName)
+
+	id, err := c.AddFollowup(ctx, agent.ID, cursor.FollowupRequest{Prompt: cursor.Prompt{Text: "Also add a license"}})
+	require.NoError(t, err)
+	require.Equal(t, agent.ID, id)
+
+	conv, err := c.GetConversation(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Len(t, conv.Messages, 2)
+
+	deleted, err := c.DeleteAgent(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Equal(t, agent.ID, deleted)
+
+	_, err = c.GetAgent(ctx, agent.ID)
+	var apiErr *cursor.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	_, err = cursor.New("wrong", cursor.WithBaseURL(srv.URL)).Me(ctx)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestWaitForAgentFollowsScript(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+
+	agent := launch(t, c, cursor.LaunchRequest{})
+	var seen []cursor.AgentStatus
+	final, err := c.WaitForAgent(context.Background(), agent.ID, &cursor.WaitOptions{
+		InitialInterval: time.Millisecond,
+		OnStatusChange:  func(a *cursor.Agent) { seen = append(seen, a.Status) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, cursor.AgentStatusFinished, final.Status)
+	require.NotNil(t, final.Summary)
+	require.Equal(t, []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, cursor.AgentStatusFinished}, seen)
+}
+
+func TestWaitForAgentFailure(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithScript(
+		cursortest.Step{Status: cursor.AgentStatusRunning, Polls: 1},
+		cursortest.Step{Status: cursor.AgentStatusError},
+	))
+	defer srv.Close()
+	c := srv.Client()
+
+	agent := launch(t, c, cursor.LaunchRequest{})
+	final, err := c.WaitForAgent(context.Background(), agent.ID, &cursor.WaitOptions{InitialInterval: time.Millisecond})
+	var failed *cursor.AgentFailedError
+	require.ErrorAs(t, err, &failed)
+	require.Equal(t, cursor.AgentStatusError, final.Status)
+}
+
+func TestRetryOnInjectedFaults(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client(cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}))
+
+	srv.InjectFault(cursortest.Fault{Method: http.MethodGet, Path: "/v0/models", StatusCode: http.StatusBadGateway, Times: 2})
+	_, err := c.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, srv.Requests(), 3)
+
+	// Launches carry an idempotency key, so they are retried on 5xx.
+	srv.InjectFault(cursortest.Fault{Method: http.MethodPost, Path: "/v0/agents", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	agent := launch(t, c, cursor.LaunchRequest{})
+
+	// Other non-idempotent requests are not retried on 5xx.
+	srv.InjectFault(cursortest.Fault{Method: http.MethodPost, Path: "/v0/agents/*/followup", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, err = c.AddFollowup(context.Background(), agent.ID, cursor.FollowupRequest{Prompt: cursor.Prompt{Text: "x"}})
+	var apiErr *cursor.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	// Rate limits are retried for every method.
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents", StatusCode: http.StatusTooManyRequests, Times: 1})
+	launch(t, c, cursor.LaunchRequest{})
+}
+
+func TestClientRateLimiterFailFast(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client(cursor.WithDefaultRateLimits(cursor.RateLimitFailFast))
+
+	_, err := c.ListRepositories(context.Background())
+	require.NoError(t, err)
+	_, err = c.ListRepositories(context.Background())
+	var rlErr *cursor.RateLimitExceededError
+	require.ErrorAs(t, err, &rlErr)
+	require.Greater(t, rlErr.RetryAfter, 50*time.Second)
+	require.Len(t, srv.Requests(), 1)
+}
+
+func TestAgentsIterator(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	for i := range 25 {
+		srv.AddAgent(cursor.Agent{ID: fmt.Sprintf("bc_%02d", i), Status: cursor.AgentStatusFinished})
+	}
+	c := srv.Client()
+
+	all, err := c.AllAgents(context.Background(), &cursor.ListAgentsOptions{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 25)
+	require.Equal(t, "bc_24", all[0].ID)
+
+	capped, err := c.AllAgents(context.Background(), &cursor.ListAgentsOptions{PageSize: 10, MaxItems: 12})
+	require.NoError(t, err)
+	require.Len(t, capped, 12)
+
+	n := 0
+	for range c.Agents(context.Background(), &cursor.ListAgentsOptions{PageSize: 4}) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	require.Equal(t, 3, n)
+}
+
+func TestLatency(t *testing.T) {
+	srv := cursortest.NewServer(cursortest.WithLatency(200 * time.Millisecond))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := srv.Client().Me(ctx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestSignedWebhooks(t *testing.T) {
+	const secret = "webhook-secret-0123456789abcdef0123"
+	var mu sync.Mutex
+	var got []cursor.WebhookEvent
+	receiver := httptest.NewServer(cursor.SignatureHandleWrapper(secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev cursor.WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ev))
+		mu.Lock()
+		got = append(got, ev)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	defer receiver.Close()
+
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+
+	agent := launch(t, c, cursor.LaunchRequest{Webhook: &cursor.LaunchWebhook{URL: receiver.URL, Secret: secret}})
+	_, err := c.WaitForAgent(context.Background(), agent.ID, &cursor.WaitOptions{InitialInterval: time.Millisecond})
+	require.NoError(t, err)
+	srv.FlushWebhooks()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, got, 2)
+	require.Equal(t, cursor.AgentStatusRunning, got[0].Status)
+	require.Equal(t, cursor.AgentStatusFinished, got[1].Status)
+	for _, d := range srv.Webhooks() {
+		require.Equal(t, http.StatusNoContent, d.StatusCode)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	_, err := c.GetAgent(ctx, "missing")
+	require.ErrorIs(t, err, cursor.ErrNotFound)
+	require.False(t, cursor.IsRetryable(err))
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second, Times: 1})
+	_, err = c.Me(ctx)
+	require.ErrorIs(t, err, cursor.ErrRateLimited)
+	var rlErr *cursor.RateLimitError
+	require.ErrorAs(t, err, &rlErr)
+	require.Equal(t, 7*time.Second, rlErr.RetryAfter)
+	require.IsType(t, &cursor.APIError{}, err)
+	require.True(t, cursor.IsTemporary(err))
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusInternalServerError, Times: 1})
+	_, err = c.Me(ctx)
+	require.ErrorIs(t, err, cursor.ErrServer)
+	require.True(t, cursor.IsRetryable(err))
+	require.False(t, cursor.IsTemporary(err))
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/me", StatusCode: http.StatusForbidden, Times: 1})
+	_, err = c.Me(ctx)
+	require.ErrorIs(t, err, cursor.ErrForbidden)
+	require.NotErrorIs(t, err, cursor.ErrUnauthorized)
+}
+
+func TestTailConversation(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	agent := launch(t, c, cursor.LaunchRequest{})
+	require.NoError(t, srv.Script(agent.ID,
+		cursortest.Step{Status: cursor.AgentStatusRunning, Polls: 3},
+		cursortest.Step{Status: cursor.AgentStatusFinished},
+	))
+
+	var got []cursor.Message
+	for m, err := range c.TailConversation(context.Background(), agent.ID, &cursor.TailOptions{Interval: time.Millisecond}) {
+		require.NoError(t, err)
+		got = append(got, m)
+		if len(got) == 1 {
+			require.NoError(t, srv.AppendMessage(agent.ID, cursor.Message{Type: "assistant_message", Text: "Working on it"}))
+		}
+	}
+	conv, err := c.GetConversation(context.Background(), agent.ID)
+	require.NoError(t, err)
+	require.Equal(t, conv.Messages, got)
+	require.Equal(t, "Working on it", got[len(got)-2].Text)
+	require.Equal(t, "Completed the requested changes.", got[len(got)-1].Text)
+
+	// SkipExisting on a finished agent yields nothing.
+	for range c.TailConversation(context.Background(), agent.ID, &cursor.TailOptions{SkipExisting: true}) {
+		t.Fatal("unexpected message")
+	}
+
+	for _, err := range c.TailConversation(context.Background(), "missing", nil) {
+		require.ErrorIs(t, err, cursor.ErrNotFound)
+	}
+
+	// Retryable failures do not end the tail, even without a retry policy.
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents/*/conversation", StatusCode: http.StatusBadGateway, Times: 2})
+	got = nil
+	for m, err := range c.TailConversation(context.Background(), agent.ID, &cursor.TailOptions{Interval: time.Millisecond}) {
+		require.NoError(t, err)
+		got = append(got, m)
+	}
+	require.Equal(t, conv.Messages, got)
+}
@@ -0,0 +1,164 @@
+package cursortest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// WebhookSimulator builds realistic webhook events for an agent lifecycle and
+// delivers them, signed, to a URL or an http.Handler.
+type WebhookSimulator struct {
+	// Secret signs every delivery. Empty sends no X-Webhook-Signature header.
+	Secret string
+	// Handler receives deliveries in-process. If nil, deliveries are POSTed to URL.
+	Handler http.Handler
+	// URL receives deliveries when Handler is nil.
+	URL string
+	// Client sends deliveries to URL (default: http.DefaultClient).
+	Client *http.Client
+	// MaxAttempts is the number of attempts per event; failed attempts (transport
+	// errors or non-2xx responses) are retried. Values below 1 mean a single attempt.
+	MaxAttempts int
+	// RetryBackoff is the delay between attempts.
+	RetryBackoff time.Duration
+	// Shuffle delivers events in random order to exercise out-of-order handling.
+	Shuffle bool
+	// Rand is used for shuffling (default: a randomly seeded source).
+	Rand *rand.Rand
+	// Now returns the timestamp of the first event (default: time.Now).
+	Now func() time.Time
+}
+
+// Lifecycle builds statusChange events for agent moving through statuses, one second
+// apart. Without statuses it simulates CREATING, RUNNING and then agent.Status,
+// or FINISHED if agent.Status is not terminal.
+func (s *WebhookSimulator) Lifecycle(agent cursor.Agent, statuses ...cursor.AgentStatus) []cursor.WebhookEvent {
+	if len(statuses) == 0 {
+		final := agent.Status
+		if !final.IsTerminal() {
+			final = cursor.AgentStatusFinished
+		}
+		statuses = []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, final}
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	start := now().UTC()
+	events := make([]cursor.WebhookEvent, 0, len(statuses))
+	for i, st := range statuses {
+		ev := cursor.WebhookEvent{
+			Event:     cursor.WebhookEventStatusChange,
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			ID:        agent.ID,
+			Status:    st,
+			Source:    agent.Source,
+			Target:    agent.Target,
+		}
+		if st == cursor.AgentStatusFinished {
+			ev.Summary = agent.Summary
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+// Deliver sends events in order (or shuffled if Shuffle is set) and returns one
+// record per event in delivery order. The error joins the failures of events that
+// were not accepted after all attempts.
+func (s *WebhookSimulator) Deliver(ctx context.Context, events ...cursor.WebhookEvent) ([]WebhookDelivery, error) {
+	events = append([]cursor.WebhookEvent(nil), events...)
+	if s.Shuffle {
+		r := s.Rand
+		if r == nil {
+			r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		}
+		r.Shuffle(len(events), func(i, j int) { events[i], events[j] = events[j], events[i] })
+	}
+
+	out := make([]WebhookDelivery, 0, len(events))
+	var errs []error
+	for _, ev := range events {
+		d := s.Send(ctx, ev)
+		out = append(out, d)
+		if d.Err != nil {
+			errs = append(errs, d.Err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// Send delivers a single event, retrying according to MaxAttempts.
+func (s *WebhookSimulator) Send(ctx context.Context, ev cursor.WebhookEvent) WebhookDelivery {
+	d := WebhookDelivery{URL: s.URL, Event: ev}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	attempts := max(s.MaxAttempts, 1)
+	for d.Attempts < attempts {
+		if d.Attempts > 0 && s.RetryBackoff > 0 {
+			select {
+			case <-ctx.Done():
+				d.Err = ctx.Err()
+				return d
+			case <-time.After(s.RetryBackoff):
+			}
+		}
+		d.Attempts++
+		d.StatusCode, d.Err = s.post(ctx, body, ev.Event)
+		if d.Err == nil && (d.StatusCode < 200 || d.StatusCode >= 300) {
+			d.Err = fmt.Errorf("cursortest: webhook for %s (%s) rejected with status %d", ev.ID, ev.Status, d.StatusCode)
+		}
+		if d.Err == nil || ctx.Err() != nil {
+			return d
+		}
+	}
+	return d
+}
+
+func (s *WebhookSimulator) post(ctx context.Context, body []byte, event string) (int, error) {
+	target := s.URL
+	if s.Handler != nil && target == "" {
+		target = "http://webhook.local/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Cursor-Agent-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", event)
+	if s.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", cursor.Sign(s.Secret, body))
+	}
+
+	if s.Handler != nil {
+		rec := httptest.NewRecorder()
+		s.Handler.ServeHTTP(rec, req)
+		return rec.Code, nil
+	}
+	hc := s.Client
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
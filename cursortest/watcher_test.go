@@ -0,0 +1,142 @@
+package cursortest_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursormock"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func collect(t *testing.T, ch <-chan cursor.AgentEvent) []cursor.AgentEvent {
+	t.Helper()
+	var events []cursor.AgentEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, ev)
+		case <-timeout:
+			t.Fatalf("watch did not finish, got %v", events)
+		}
+	}
+}
+
+func statuses(events []cursor.AgentEvent) []cursor.AgentStatus {
+	out := make([]cursor.AgentStatus, len(events))
+	for i, ev := range events {
+		out[i] = ev.Status
+	}
+	return out
+}
+
+// requireLifecycle checks that got follows lifecycle in order up to its last
+// status. Statuses superseded before the watcher observed them may be missing.
+func requireLifecycle(t *testing.T, lifecycle, got []cursor.AgentStatus) {
+	t.Helper()
+	require.NotEmpty(t, got)
+	i := 0
+	for _, status := range got {
+		for i < len(lifecycle) && lifecycle[i] != status {
+			i++
+		}
+		require.Less(t, i, len(lifecycle), "status %s out of order in %v", status, got)
+		i++
+	}
+	require.Equal(t, lifecycle[len(lifecycle)-1], got[len(got)-1])
+}
+
+func TestWatcher(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	w := cursor.NewWatcher(c, &cursor.WatcherOptions{PollInterval: time.Millisecond})
+	router, err := cursor.NewWebhookRouter(testWebhookSecret)
+	require.NoError(t, err)
+	router.OnAny(w.HandleWebhook)
+	receiver := httptest.NewServer(router)
+	defer receiver.Close()
+
+	lifecycle := []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, cursor.AgentStatusFinished}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	all := w.WatchAll(ctx)
+
+	polled := launch(t, c, cursor.LaunchRequest{})
+	events := collect(t, w.Watch(ctx, polled.ID))
+	requireLifecycle(t, lifecycle, statuses(events))
+	for _, ev := range events {
+		require.Equal(t, cursor.EventSourcePoll, ev.Source)
+		require.Equal(t, polled.ID, ev.Agent.ID)
+	}
+
+	hooked := launch(t, c, cursor.LaunchRequest{Webhook: &cursor.LaunchWebhook{URL: receiver.URL, Secret: testWebhookSecret}})
+	ch := w.Watch(ctx, hooked.ID)
+	for ev := range ch {
+		if ev.Status == cursor.AgentStatusRunning {
+			break
+		}
+		require.Equal(t, cursor.AgentStatusCreating, ev.Status)
+	}
+	// Once webhooks arrive, polling slows down and the webhook reports the change.
+	srv.FlushWebhooks()
+	require.NoError(t, srv.SetStatus(hooked.ID, cursor.AgentStatusFinished))
+	events = collect(t, ch)
+	require.Equal(t, []cursor.AgentStatus{cursor.AgentStatusFinished}, statuses(events))
+
+	seen := map[string][]cursor.AgentStatus{}
+	for len(seen[polled.ID]) == 0 || len(seen[hooked.ID]) == 0 ||
+		seen[polled.ID][len(seen[polled.ID])-1] != cursor.AgentStatusFinished ||
+		seen[hooked.ID][len(seen[hooked.ID])-1] != cursor.AgentStatusFinished {
+		select {
+		case ev := <-all:
+			last := seen[ev.AgentID]
+			require.True(t, len(last) == 0 || last[len(last)-1] != ev.Status, "duplicate status %s", ev.Status)
+			seen[ev.AgentID] = append(last, ev.Status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("WatchAll did not observe both agents finishing: %v", seen)
+		}
+	}
+	cancel()
+	for range all {
+	}
+}
+
+func TestWatcherWebhookOrdering(t *testing.T) {
+	mock := &cursormock.Client{
+		GetAgentFunc: func(ctx context.Context, id string) (*cursor.Agent, error) {
+			return &cursor.Agent{ID: id, Status: cursor.AgentStatusRunning}, nil
+		},
+	}
+	w := cursor.NewWatcher(mock, &cursor.WatcherOptions{PollInterval: time.Millisecond, WebhookPollInterval: -1})
+	ctx := context.Background()
+	ch := w.Watch(ctx, "bc_1")
+
+	first := <-ch
+	require.Equal(t, cursor.AgentStatusRunning, first.Status)
+	require.Equal(t, cursor.EventSourcePoll, first.Source)
+
+	hook := func(status cursor.AgentStatus, ts time.Time) {
+		require.NoError(t, w.HandleWebhook(ctx, &cursor.WebhookEvent{Event: cursor.WebhookEventStatusChange, ID: "bc_1", Status: status, Timestamp: ts}))
+	}
+	// A status that cannot follow RUNNING is dropped whatever its timestamp,
+	// and does not count as a working webhook: polling continues.
+	hook(cursor.AgentStatusCreating, first.Time.Add(time.Hour))
+	polls := len(mock.Calls("GetAgent"))
+	require.Eventually(t, func() bool { return len(mock.Calls("GetAgent")) > polls+1 }, time.Second, time.Millisecond)
+
+	// The server clock may be behind the local one; the transition still counts.
+	hook(cursor.AgentStatusFinished, first.Time.Add(-time.Hour))
+	events := collect(t, ch)
+	require.Len(t, events, 1)
+	require.Equal(t, cursor.AgentStatusFinished, events[0].Status)
+	require.Equal(t, cursor.EventSourceWebhook, events[0].Source)
+}
@@ -0,0 +1,266 @@
+package cursortest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+const testWebhookSecret = "webhook-secret-0123456789abcdef0123"
+
+func TestWebhookRouter(t *testing.T) {
+	router, err := cursor.NewWebhookRouter(testWebhookSecret)
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) cursor.WebhookHandlerFunc {
+		return func(ctx context.Context, ev *cursor.WebhookEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, name+":"+string(ev.Status))
+			return nil
+		}
+	}
+	router.OnAny(record("any"))
+	router.OnStatusChange(record("change"))
+	router.OnFinished(record("finished"))
+	router.OnError(record("error"))
+	receiver := httptest.NewServer(router)
+	defer receiver.Close()
+
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	agent := launch(t, c, cursor.LaunchRequest{Webhook: &cursor.LaunchWebhook{URL: receiver.URL, Secret: testWebhookSecret}})
+	_, err = c.WaitForAgent(context.Background(), agent.ID, &cursor.WaitOptions{InitialInterval: time.Millisecond})
+	require.NoError(t, err)
+	srv.FlushWebhooks()
+
+	mu.Lock()
+	require.Equal(t, []string{
+		"any:RUNNING", "change:RUNNING",
+		"any:FINISHED", "change:FINISHED", "finished:FINISHED",
+	}, calls)
+	mu.Unlock()
+	for _, d := range srv.Webhooks() {
+		require.Equal(t, http.StatusNoContent, d.StatusCode)
+	}
+}
+
+func TestWebhookRouterErrors(t *testing.T) {
+	router, err := cursor.NewWebhookRouter("", cursor.WithoutSignatureVerification())
+	require.NoError(t, err)
+	var reported []error
+	router.OnHandlerError(func(r *http.Request, ev *cursor.WebhookEvent, err error) { reported = append(reported, err) })
+	router.OnFinished(func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		return cursor.NewWebhookError(http.StatusUnprocessableEntity, errors.New("unknown agent"))
+	})
+	router.OnError(func(ctx context.Context, ev *cursor.WebhookEvent) error { panic("boom") })
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec.Code
+	}
+	event := func(status cursor.AgentStatus) string {
+		b, err := json.Marshal(cursor.WebhookEvent{Event: "statusChange", ID: "bc_1", Status: status, Timestamp: time.Now()})
+		require.NoError(t, err)
+		return string(b)
+	}
+	require.Equal(t, http.StatusUnprocessableEntity, post(event(cursor.AgentStatusFinished)))
+	require.Equal(t, http.StatusInternalServerError, post(event(cursor.AgentStatusError)))
+	require.Equal(t, http.StatusNoContent, post(event(cursor.AgentStatusRunning)))
+	require.Equal(t, http.StatusBadRequest, post(`not json`))
+
+	require.Len(t, reported, 2)
+	var perr *cursor.WebhookPanicError
+	require.ErrorAs(t, reported[1], &perr)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	_, err = cursor.NewWebhookRouter("")
+	require.ErrorIs(t, err, cursor.ErrNoWebhookSecret)
+	signed, err := cursor.NewWebhookRouter(testWebhookSecret)
+	require.NoError(t, err)
+	rec = httptest.NewRecorder()
+	signed.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestWebhookReplayProtection(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var dispatched int
+	failNext := false
+	router, err := cursor.NewWebhookRouter(testWebhookSecret,
+		cursor.WithTimestampTolerance(time.Minute),
+		cursor.WithVerifierClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+	router.OnAny(func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		if failNext {
+			failNext = false
+			return errors.New("temporary failure")
+		}
+		dispatched++
+		return nil
+	})
+
+	deliver := func(ev cursor.WebhookEvent) int {
+		body, err := json.Marshal(ev)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body)))
+		req.Header.Set("X-Webhook-Signature", cursor.Sign(testWebhookSecret, body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	ev := cursor.WebhookEvent{Event: "statusChange", ID: "bc_1", Status: cursor.AgentStatusFinished, Timestamp: now.Add(-30 * time.Second)}
+	require.Equal(t, http.StatusNoContent, deliver(ev))
+	require.Equal(t, http.StatusNoContent, deliver(ev)) // duplicate is acknowledged
+	require.Equal(t, 1, dispatched)
+
+	stale := ev
+	stale.Timestamp = now.Add(-2 * time.Minute)
+	require.Equal(t, http.StatusBadRequest, deliver(stale))
+
+	// A failed delivery is forgotten so the sender's retry is processed.
+	retried := ev
+	retried.Timestamp = now
+	failNext = true
+	require.Equal(t, http.StatusInternalServerError, deliver(retried))
+	require.Equal(t, http.StatusNoContent, deliver(retried))
+	require.Equal(t, 2, dispatched)
+}
+
+func TestLRUDeliveryStore(t *testing.T) {
+	s := cursor.NewLRUDeliveryStore(2)
+	require.True(t, s.Add("a"))
+	require.True(t, s.Add("b"))
+	require.False(t, s.Add("a"))
+	require.True(t, s.Add("c")) // evicts "b", the least recently used
+	require.True(t, s.Add("b"))
+	s.Remove("b")
+	require.True(t, s.Add("b"))
+}
+
+func TestWebhookVerifierRequiresSecret(t *testing.T) {
+	_, err := cursor.NewWebhookVerifier("")
+	require.ErrorIs(t, err, cursor.ErrNoWebhookSecret)
+	_, err = cursor.NewWebhookVerifier("", cursor.WithSecrets(cursor.WebhookSecret{ID: "k1", Secret: testWebhookSecret}))
+	require.NoError(t, err)
+
+	body, err := json.Marshal(cursor.WebhookEvent{Event: "statusChange", ID: "bc_1", Status: cursor.AgentStatusRunning, Timestamp: time.Now()})
+	require.NoError(t, err)
+	v, err := cursor.NewWebhookVerifier("", cursor.WithoutSignatureVerification())
+	require.NoError(t, err)
+	d, err := v.Verify(httptest.NewRequest(http.MethodPost, "/", nil), body)
+	require.NoError(t, err)
+	require.Empty(t, d.KeyID)
+	_, err = v.Verify(httptest.NewRequest(http.MethodPost, "/", nil), body)
+	require.ErrorIs(t, err, cursor.ErrDuplicateWebhook, "replay checks still apply")
+}
+
+func TestWebhookSecretRotation(t *testing.T) {
+	body, err := json.Marshal(cursor.WebhookEvent{Event: "statusChange", ID: "bc_1", Status: cursor.AgentStatusRunning, Timestamp: time.Now()})
+	require.NoError(t, err)
+	request := func(secret string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Webhook-Signature", cursor.Sign(secret, body))
+		return req
+	}
+
+	v, err := cursor.NewWebhookVerifier("new-secret",
+		cursor.WithSecrets(cursor.WebhookSecret{ID: "previous", Secret: "old-secret"}),
+		cursor.WithDeliveryStore(nil),
+	)
+	require.NoError(t, err)
+	d, err := v.Verify(request("old-secret"), body)
+	require.NoError(t, err)
+	require.Equal(t, "previous", d.KeyID)
+	d, err = v.Verify(request("new-secret"), body)
+	require.NoError(t, err)
+	require.Equal(t, cursor.PrimarySecretID, d.KeyID)
+	_, err = v.Verify(request("other-secret"), body)
+	require.ErrorIs(t, err, cursor.ErrInvalidSignature)
+
+	provided, err := cursor.NewWebhookVerifier("", cursor.WithSecretProvider(func(r *http.Request) ([]cursor.WebhookSecret, error) {
+		if r.URL.Query().Get("tenant") == "" {
+			return nil, errors.New("unknown tenant")
+		}
+		return []cursor.WebhookSecret{{ID: "tenant-key", Secret: "old-secret"}}, nil
+	}))
+	require.NoError(t, err)
+	_, err = provided.Verify(request("old-secret"), body)
+	require.ErrorIs(t, err, cursor.ErrSecretUnavailable)
+
+	router, err := cursor.NewWebhookRouter("", cursor.WithSecretProvider(func(r *http.Request) ([]cursor.WebhookSecret, error) {
+		return []cursor.WebhookSecret{{ID: "k1", Secret: "new-secret"}, {ID: "k0", Secret: "old-secret"}}, nil
+	}))
+	require.NoError(t, err)
+	var keyID string
+	router.OnAny(func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		d, _ := cursor.WebhookDeliveryFromContext(ctx)
+		keyID = d.KeyID
+		return nil
+	})
+	req := request("old-secret")
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "k0", keyID)
+}
+
+func TestWebhookSimulator(t *testing.T) {
+	router, err := cursor.NewWebhookRouter(testWebhookSecret)
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var statuses []cursor.AgentStatus
+	failures := 1
+	router.OnAny(func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ev.Status == cursor.AgentStatusRunning && failures > 0 {
+			failures--
+			return errors.New("flaky receiver")
+		}
+		statuses = append(statuses, ev.Status)
+		return nil
+	})
+
+	sim := &cursortest.WebhookSimulator{Secret: testWebhookSecret, Handler: router, MaxAttempts: 2}
+	agent := cursor.Agent{ID: "bc_sim", Status: cursor.AgentStatusError, Source: cursor.Source{Repository: "https://github.com/octocat/hello-world"}}
+	events := sim.Lifecycle(agent)
+	require.Len(t, events, 3)
+	require.Equal(t, cursor.AgentStatusError, events[2].Status)
+
+	deliveries, err := sim.Deliver(context.Background(), events...)
+	require.NoError(t, err)
+	require.Equal(t, 2, deliveries[1].Attempts)
+	require.Equal(t, []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, cursor.AgentStatusError}, statuses)
+
+	// Over HTTP, with a wrong secret every attempt is rejected.
+	receiver := httptest.NewServer(router)
+	defer receiver.Close()
+	bad := &cursortest.WebhookSimulator{Secret: "wrong", URL: receiver.URL, MaxAttempts: 2}
+	deliveries, err = bad.Deliver(context.Background(), bad.Lifecycle(agent, cursor.AgentStatusFinished)...)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, deliveries[0].StatusCode)
+	require.Equal(t, 2, deliveries[0].Attempts)
+}
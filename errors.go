@@ -1,7 +1,23 @@
 package cursor
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Sentinel errors for classifying API failures with errors.Is.
+var (
+	ErrNotFound     = errors.New("cursor: not found")
+	ErrUnauthorized = errors.New("cursor: unauthorized")
+	ErrForbidden    = errors.New("cursor: forbidden")
+	ErrRateLimited  = errors.New("cursor: rate limited")
+	ErrConflict     = errors.New("cursor: conflict")
+	ErrServer       = errors.New("cursor: server error")
 )
 
 // APIError represents a non-2xx HTTP response from the Cursor API.
@@ -11,6 +27,12 @@ type APIError struct {
 	Message    string
 	Code       string
 	Body       string
+	// Meta describes the HTTP response, including its headers and request ID.
+	// It is nil for errors not returned by the Client.
+	Meta *ResponseMeta
+
+	// retryAfter is the delay requested by the server via the Retry-After header.
+	retryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -22,3 +44,116 @@ func (e *APIError) Error() string {
 	}
 	return fmt.Sprintf("API error: status=%d body=%s", e.StatusCode, e.Body)
 }
+
+// Is matches the sentinel error corresponding to the HTTP status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// As lets errors.As extract a *RateLimitError from a 429 response.
+func (e *APIError) As(target any) bool {
+	rl, ok := target.(**RateLimitError)
+	if !ok || e.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	*rl = &RateLimitError{APIError: e, RetryAfter: e.retryAfter}
+	return true
+}
+
+// RateLimitError describes a 429 response. The Client returns every non-2xx
+// response as *APIError; use errors.As to get a *RateLimitError from a 429 one.
+type RateLimitError struct {
+	*APIError
+	// RetryAfter is the delay requested by the server, or zero if none was sent.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Unwrap() error { return e.APIError }
+
+// IsTemporary reports whether err is caused by a transient condition that is
+// expected to clear on its own: rate limiting, an overloaded or unreachable
+// upstream (502, 503, 504) or a network timeout.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if isContextError(err) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsRetryable reports whether repeating the request that failed with err may succeed.
+// It covers temporary errors, any 5xx response and transport failures,
+// including http.Client timeouts. Errors returned because the caller's context
+// was canceled or expired are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isContextError(err) {
+		return false
+	}
+	if IsTemporary(err) || errors.Is(err, ErrServer) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// isContextError reports whether err wraps context.Canceled or
+// context.DeadlineExceeded itself, as returned when the caller's context is
+// done. Timeouts that only match those errors through an Is method, such as
+// http.Client.Timeout, are not context errors.
+func isContextError(err error) bool {
+	for err != nil {
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if isContextError(e) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
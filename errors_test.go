@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorClassification(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		err                  error
+		temporary, retryable bool
+	}{
+		{"nil", nil, false, false},
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true, true},
+		{"bad gateway", &APIError{StatusCode: http.StatusBadGateway}, true, true},
+		{"internal error", &APIError{StatusCode: http.StatusInternalServerError}, false, true},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false, false},
+		{"canceled", context.Canceled, false, false},
+		{"caller deadline", errors.Join(errors.New("request failed"), context.DeadlineExceeded), false, false},
+		{"other", errors.New("boom"), false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.temporary, IsTemporary(tt.err))
+			require.Equal(t, tt.retryable, IsRetryable(tt.err))
+		})
+	}
+}
+
+// slowServer delays the first slow requests by a second and answers the rest at once.
+func slowServer(t *testing.T, slow int32) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body) // lets the server notice the client going away
+		if requests.Add(1) <= slow {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		_, _ = w.Write([]byte(`{"models":["gpt-5"]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestTransportTimeoutIsRetried(t *testing.T) {
+	srv, requests := slowServer(t, 1)
+	hc := &http.Client{Timeout: 50 * time.Millisecond}
+
+	// An http.Client timeout matches context.DeadlineExceeded but is not the
+	// caller's deadline.
+	_, err := hc.Get(srv.URL)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, IsTemporary(err))
+	require.True(t, IsRetryable(err))
+
+	requests.Store(0)
+	c := New("key", WithBaseURL(srv.URL), WithHTTPClient(hc), WithRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	var meta ResponseMeta
+	_, err = c.ListModels(context.Background(), WithResponseMeta(&meta))
+	require.NoError(t, err)
+	require.Equal(t, 2, meta.Attempts)
+}
+
+func TestTransportTimeoutRetriesOnlyIdempotentPosts(t *testing.T) {
+	srv, requests := slowServer(t, 1)
+	hc := &http.Client{Timeout: 50 * time.Millisecond}
+	c := New("key", WithBaseURL(srv.URL), WithHTTPClient(hc), WithRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+
+	// The server may have acted on a POST whose response timed out.
+	_, err := c.AddFollowup(context.Background(), "bc_1", FollowupRequest{Prompt: Prompt{Text: "x"}})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualValues(t, 1, requests.Load())
+
+	// Launches carry a generated idempotency key, so they are retried.
+	requests.Store(0)
+	var meta ResponseMeta
+	_, err = c.LaunchAgent(context.Background(), LaunchRequest{}, WithResponseMeta(&meta))
+	require.NoError(t, err)
+	require.Equal(t, 2, meta.Attempts)
+}
+
+func TestCallerDeadlineIsNotRetried(t *testing.T) {
+	srv, requests := slowServer(t, 10)
+	c := New("key", WithBaseURL(srv.URL), WithRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.ListModels(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, IsTemporary(err))
+	require.False(t, IsRetryable(err))
+	require.EqualValues(t, 1, requests.Load())
+}
+
+func TestRateLimitErrorAs(t *testing.T) {
+	var err error = &APIError{StatusCode: http.StatusTooManyRequests, retryAfter: 7 * time.Second}
+	var rl *RateLimitError
+	require.ErrorAs(t, err, &rl)
+	require.Equal(t, 7*time.Second, rl.RetryAfter)
+	require.Same(t, err, rl.APIError)
+
+	require.False(t, errors.As(&APIError{StatusCode: http.StatusBadGateway}, &rl))
+}
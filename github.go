@@ -9,9 +9,10 @@ import (
 // Limit requests to 1 / user / minute, and 30 / user / hour.
 // This request can take tens of seconds to respond for users with access to many repositories.
 // Make sure to handle this information not being available gracefully.
-func (c *Client) ListRepositories(ctx context.Context) (*ListRepositoriesResponse, error) {
+// Use WithDefaultRateLimits to enforce the documented limits on the client side.
+func (c *Client) ListRepositories(ctx context.Context, opts ...CallOption) (*ListRepositoriesResponse, error) {
 	var out ListRepositoriesResponse
-	if err := c.do(ctx, "GET", "/v0/repositories", nil, nil, &out); err != nil {
+	if err := c.do(ctx, OpListRepositories, "GET", "/v0/repositories", nil, nil, &out, opts); err != nil {
 		return nil, err
 	}
 	return &out, nil
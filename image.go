@@ -0,0 +1,340 @@
+package cursor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+)
+
+// ImageFormat is an image encoding detected from its leading bytes.
+type ImageFormat string
+
+// Supported image formats.
+const (
+	ImagePNG  ImageFormat = "png"
+	ImageJPEG ImageFormat = "jpeg"
+	ImageGIF  ImageFormat = "gif"
+	ImageWebP ImageFormat = "webp"
+)
+
+// Image limits applied by the NewImageFrom* constructors and Prompt.AddImage
+// unless overridden by ImageOptions.
+const (
+	DefaultMaxImageBytes     = 5 << 20
+	DefaultMaxImageDimension = 8000
+	MaxPromptImages          = 5
+)
+
+// Hard limits on input that may be resized, protecting against images that
+// are small when encoded but huge when decoded.
+const (
+	MaxImageInputBytes = 50 << 20
+	MaxImagePixels     = 50_000_000
+)
+
+// Errors returned by the image helpers.
+var (
+	ErrUnsupportedImage = errors.New("cursor: unsupported image format")
+	ErrImageTooLarge    = errors.New("cursor: image too large")
+	ErrTooManyImages    = errors.New("cursor: too many images")
+)
+
+// ImageOptions configures the NewImageFrom* constructors. The zero value uses
+// the default limits and rejects oversized images.
+type ImageOptions struct {
+	// MaxBytes limits the size of the encoded image before base64 encoding
+	// (default: DefaultMaxImageBytes).
+	MaxBytes int
+	// MaxWidth and MaxHeight limit the dimensions in pixels
+	// (default: DefaultMaxImageDimension).
+	MaxWidth  int
+	MaxHeight int
+	// Resize downscales and re-encodes images exceeding the limits instead of
+	// returning ErrImageTooLarge. JPEG input stays JPEG; PNG and GIF input is
+	// re-encoded as PNG, or as JPEG if PNG is still too large and the image has
+	// no transparency. WebP images cannot be resized. Input larger than
+	// MaxImageInputBytes or MaxImagePixels is always rejected.
+	Resize bool
+	// JPEGQuality is the quality used when re-encoding to JPEG (default: 85).
+	JPEGQuality int
+}
+
+func (o *ImageOptions) withDefaults() ImageOptions {
+	var out ImageOptions
+	if o != nil {
+		out = *o
+	}
+	if out.MaxBytes <= 0 {
+		out.MaxBytes = DefaultMaxImageBytes
+	}
+	if out.MaxWidth <= 0 {
+		out.MaxWidth = DefaultMaxImageDimension
+	}
+	if out.MaxHeight <= 0 {
+		out.MaxHeight = DefaultMaxImageDimension
+	}
+	if out.JPEGQuality <= 0 || out.JPEGQuality > 100 {
+		out.JPEGQuality = 85
+	}
+	return out
+}
+
+// NewImageFromFile reads an image file and returns it as an Image with its
+// dimensions. opts may be nil.
+func NewImageFromFile(path string, opts *ImageOptions) (Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Image{}, err
+	}
+	defer f.Close()
+	return NewImageFromReader(f, opts)
+}
+
+// NewImageFromReader reads a PNG, JPEG, GIF or WebP image from r and returns it
+// as an Image with its dimensions. The data is sent unchanged unless it exceeds
+// the limits and opts.Resize is set. opts may be nil.
+func NewImageFromReader(r io.Reader, opts *ImageOptions) (Image, error) {
+	o := opts.withDefaults()
+	// Read one byte past the limit to detect oversized input without reading it
+	// all. Input that may be resized is capped at MaxImageInputBytes instead.
+	limit := o.MaxBytes
+	if o.Resize {
+		limit = max(limit, MaxImageInputBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return Image{}, err
+	}
+	format, err := DetectImageFormat(data)
+	if err != nil {
+		return Image{}, err
+	}
+	if len(data) > limit {
+		return Image{}, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, limit)
+	}
+	w, h, err := imageSize(format, data)
+	if err != nil {
+		return Image{}, err
+	}
+	if len(data) <= o.MaxBytes && w <= o.MaxWidth && h <= o.MaxHeight {
+		return encodeImage(data, w, h), nil
+	}
+	if !o.Resize {
+		return Image{}, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrImageTooLarge, w, h, o.MaxWidth, o.MaxHeight)
+	}
+	if format == ImageWebP {
+		return Image{}, fmt.Errorf("%w: cannot resize WebP images", ErrUnsupportedImage)
+	}
+	if w*h > MaxImagePixels {
+		return Image{}, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, w, h, MaxImagePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, err
+	}
+	return reencode(img, format == ImageJPEG, o)
+}
+
+// NewImageFromImage encodes img as PNG (downscaling it if it exceeds the limits
+// and opts.Resize is set) and returns it as an Image. opts may be nil.
+func NewImageFromImage(img image.Image, opts *ImageOptions) (Image, error) {
+	o := opts.withDefaults()
+	b := img.Bounds()
+	if !o.Resize {
+		if b.Dx() > o.MaxWidth || b.Dy() > o.MaxHeight {
+			return Image{}, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrImageTooLarge, b.Dx(), b.Dy(), o.MaxWidth, o.MaxHeight)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return Image{}, err
+		}
+		if buf.Len() > o.MaxBytes {
+			return Image{}, fmt.Errorf("%w: more than %d bytes", ErrImageTooLarge, o.MaxBytes)
+		}
+		return encodeImage(buf.Bytes(), b.Dx(), b.Dy()), nil
+	}
+	return reencode(img, false, o)
+}
+
+// AddImage appends img to the prompt, enforcing MaxPromptImages.
+func (p *Prompt) AddImage(img Image) error {
+	if len(p.Images) >= MaxPromptImages {
+		return fmt.Errorf("%w: at most %d per prompt", ErrTooManyImages, MaxPromptImages)
+	}
+	p.Images = append(p.Images, img)
+	return nil
+}
+
+// DetectImageFormat identifies a PNG, JPEG, GIF or WebP image by its leading bytes.
+func DetectImageFormat(data []byte) (ImageFormat, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ImagePNG, nil
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8, 0xff}):
+		return ImageJPEG, nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return ImageGIF, nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return ImageWebP, nil
+	}
+	return "", ErrUnsupportedImage
+}
+
+func encodeImage(data []byte, w, h int) Image {
+	return Image{
+		Data:      base64.StdEncoding.EncodeToString(data),
+		Dimension: &Dimension{Width: w, Height: h},
+	}
+}
+
+// imageSize returns the dimensions of an encoded image without decoding its pixels.
+func imageSize(format ImageFormat, data []byte) (int, int, error) {
+	var (
+		cfg image.Config
+		err error
+	)
+	r := bytes.NewReader(data)
+	switch format {
+	case ImagePNG:
+		cfg, err = png.DecodeConfig(r)
+	case ImageJPEG:
+		cfg, err = jpeg.DecodeConfig(r)
+	case ImageGIF:
+		cfg, err = gif.DecodeConfig(r)
+	case ImageWebP:
+		return webpSize(data)
+	default:
+		return 0, 0, ErrUnsupportedImage
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpSize parses the dimensions from the first chunk of a WebP file
+// (lossy VP8, lossless VP8L or extended VP8X).
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, fmt.Errorf("%w: truncated WebP header", ErrUnsupportedImage)
+	}
+	chunk := data[12:16]
+	p := data[20:]
+	switch string(chunk) {
+	case "VP8 ":
+		// Frame tag (3 bytes), start code 9d 01 2a, then 14-bit width and height.
+		if p[3] != 0x9d || p[4] != 0x01 || p[5] != 0x2a {
+			return 0, 0, fmt.Errorf("%w: bad VP8 start code", ErrUnsupportedImage)
+		}
+		w := int(binary.LittleEndian.Uint16(p[6:8]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(p[8:10]) & 0x3fff)
+		return w, h, nil
+	case "VP8L":
+		// Signature 0x2f, then 14-bit width-1 and height-1.
+		if p[0] != 0x2f {
+			return 0, 0, fmt.Errorf("%w: bad VP8L signature", ErrUnsupportedImage)
+		}
+		bits := binary.LittleEndian.Uint32(p[1:5])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+	case "VP8X":
+		// Flags (4 bytes), then 24-bit canvas width-1 and height-1.
+		w := int(p[4]) | int(p[5])<<8 | int(p[6])<<16
+		h := int(p[7]) | int(p[8])<<8 | int(p[9])<<16
+		return w + 1, h + 1, nil
+	}
+	return 0, 0, fmt.Errorf("%w: unknown WebP chunk %q", ErrUnsupportedImage, chunk)
+}
+
+// reencode downscales img to fit the limits and encodes it, halving the size
+// until the encoded image fits MaxBytes. Images with transparency are never
+// encoded as JPEG.
+func reencode(img image.Image, preferJPEG bool, o ImageOptions) (Image, error) {
+	b := img.Bounds()
+	allowJPEG := preferJPEG || isOpaque(img)
+	w, h := fitWithin(b.Dx(), b.Dy(), o.MaxWidth, o.MaxHeight)
+	for {
+		scaled := img
+		if w != b.Dx() || h != b.Dy() {
+			scaled = downscale(img, w, h)
+		}
+		var buf bytes.Buffer
+		if !preferJPEG {
+			if err := png.Encode(&buf, scaled); err != nil {
+				return Image{}, err
+			}
+		}
+		if preferJPEG || (allowJPEG && buf.Len() > o.MaxBytes) {
+			buf.Reset()
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: o.JPEGQuality}); err != nil {
+				return Image{}, err
+			}
+		}
+		if buf.Len() <= o.MaxBytes {
+			return encodeImage(buf.Bytes(), w, h), nil
+		}
+		if w == 1 && h == 1 {
+			return Image{}, fmt.Errorf("%w: cannot fit in %d bytes", ErrImageTooLarge, o.MaxBytes)
+		}
+		w, h = max(w/2, 1), max(h/2, 1)
+	}
+}
+
+// isOpaque reports whether img is known to have no transparent pixels.
+func isOpaque(img image.Image) bool {
+	o, ok := img.(interface{ Opaque() bool })
+	return ok && o.Opaque()
+}
+
+// fitWithin scales w×h down to fit maxW×maxH, keeping the aspect ratio.
+func fitWithin(w, h, maxW, maxH int) (int, int) {
+	if w <= maxW && h <= maxH {
+		return w, h
+	}
+	if w*maxH > h*maxW {
+		return maxW, max(h*maxW/w, 1)
+	}
+	return max(w*maxH/h, 1), maxH
+}
+
+// downscale resizes img to w×h by averaging the source pixels covered by each
+// destination pixel (a box filter).
+func downscale(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := range w {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					px := row[sx*4 : sx*4+4]
+					r += uint64(px[0])
+					g += uint64(px[1])
+					bl += uint64(px[2])
+					a += uint64(px[3])
+					n++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(bl/n), uint8(a/n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,176 @@
+package cursor_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(r.IntN(256))
+	}
+	return img
+}
+
+func TestImageHelpers(t *testing.T) {
+	src := testImage(40, 30)
+	var pngData, jpegData, gifData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, src))
+	require.NoError(t, jpeg.Encode(&jpegData, src, nil))
+	require.NoError(t, gif.Encode(&gifData, src, nil))
+
+	path := filepath.Join(t.TempDir(), "shot.png")
+	require.NoError(t, os.WriteFile(path, pngData.Bytes(), 0o600))
+	img, err := cursor.NewImageFromFile(path, nil)
+	require.NoError(t, err)
+	require.Equal(t, &cursor.Dimension{Width: 40, Height: 30}, img.Dimension)
+	require.Equal(t, base64.StdEncoding.EncodeToString(pngData.Bytes()), img.Data)
+
+	for _, data := range [][]byte{jpegData.Bytes(), gifData.Bytes()} {
+		img, err := cursor.NewImageFromReader(bytes.NewReader(data), nil)
+		require.NoError(t, err)
+		require.Equal(t, &cursor.Dimension{Width: 40, Height: 30}, img.Dimension)
+	}
+
+	// WebP headers: lossless (VP8L) and extended (VP8X).
+	vp8l := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f"), make([]byte, 16)...)
+	binary.LittleEndian.PutUint32(vp8l[21:], uint32(40-1)|uint32(30-1)<<14)
+	img, err = cursor.NewImageFromReader(bytes.NewReader(vp8l), nil)
+	require.NoError(t, err)
+	require.Equal(t, &cursor.Dimension{Width: 40, Height: 30}, img.Dimension)
+	vp8x := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00"), 0x1f, 0x0e, 0x00, 0xff, 0x00, 0x00, 0, 0, 0, 0)
+	img, err = cursor.NewImageFromReader(bytes.NewReader(vp8x), nil)
+	require.NoError(t, err)
+	require.Equal(t, &cursor.Dimension{Width: 3616, Height: 256}, img.Dimension)
+	_, err = cursor.NewImageFromReader(bytes.NewReader(vp8x), &cursor.ImageOptions{MaxWidth: 3000})
+	require.ErrorIs(t, err, cursor.ErrImageTooLarge)
+	_, err = cursor.NewImageFromReader(bytes.NewReader(vp8x), &cursor.ImageOptions{MaxWidth: 3000, Resize: true})
+	require.ErrorIs(t, err, cursor.ErrUnsupportedImage)
+
+	_, err = cursor.NewImageFromReader(bytes.NewReader([]byte("not an image")), nil)
+	require.ErrorIs(t, err, cursor.ErrUnsupportedImage)
+
+	// Oversized images are rejected, or downscaled with Resize.
+	big := testImage(400, 100)
+	_, err = cursor.NewImageFromImage(big, &cursor.ImageOptions{MaxWidth: 200})
+	require.ErrorIs(t, err, cursor.ErrImageTooLarge)
+	img, err = cursor.NewImageFromImage(big, &cursor.ImageOptions{MaxWidth: 200, Resize: true})
+	require.NoError(t, err)
+	require.Equal(t, &cursor.Dimension{Width: 200, Height: 50}, img.Dimension)
+	decoded, err := base64.StdEncoding.DecodeString(img.Data)
+	require.NoError(t, err)
+	cfg, err := png.DecodeConfig(bytes.NewReader(decoded))
+	require.NoError(t, err)
+	require.Equal(t, 200, cfg.Width)
+
+	_, err = cursor.NewImageFromReader(bytes.NewReader(pngData.Bytes()), &cursor.ImageOptions{MaxBytes: 1000})
+	require.ErrorIs(t, err, cursor.ErrImageTooLarge)
+	img, err = cursor.NewImageFromReader(bytes.NewReader(pngData.Bytes()), &cursor.ImageOptions{MaxBytes: 1000, Resize: true})
+	require.NoError(t, err)
+	decoded, err = base64.StdEncoding.DecodeString(img.Data)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(decoded), 1000)
+	_, _, err = image.Decode(bytes.NewReader(decoded))
+	require.NoError(t, err)
+
+	var p cursor.Prompt
+	for range cursor.MaxPromptImages {
+		require.NoError(t, p.AddImage(img))
+	}
+	require.ErrorIs(t, p.AddImage(img), cursor.ErrTooManyImages)
+
+	// Downscaling averages pixels.
+	flat := image.NewUniform(color.RGBA{R: 200, G: 100, B: 50, A: 255})
+	img, err = cursor.NewImageFromImage(&boundedImage{flat, image.Rect(0, 0, 64, 64)}, &cursor.ImageOptions{MaxWidth: 16, MaxHeight: 16, Resize: true})
+	require.NoError(t, err)
+	decoded, _ = base64.StdEncoding.DecodeString(img.Data)
+	out, err := png.Decode(bytes.NewReader(decoded))
+	require.NoError(t, err)
+	r, g, b, _ := out.At(5, 5).RGBA()
+	require.Equal(t, []uint32{200, 100, 50}, []uint32{r >> 8, g >> 8, b >> 8})
+}
+
+func TestImageDecodeLimits(t *testing.T) {
+	// A tiny PNG whose header claims 100000x100000 pixels is rejected before decoding.
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	bomb := buf.Bytes()
+	ihdr := bomb[8+8 : 8+8+13] // after the signature and the chunk length and type
+	binary.BigEndian.PutUint32(ihdr[0:4], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 100000)
+	binary.BigEndian.PutUint32(bomb[8+8+13:], crc32.ChecksumIEEE(bomb[8+4:8+8+13]))
+	_, err := cursor.NewImageFromReader(bytes.NewReader(bomb), &cursor.ImageOptions{Resize: true})
+	require.ErrorIs(t, err, cursor.ErrImageTooLarge)
+	require.ErrorContains(t, err, "pixels")
+
+	// Input that may be resized is still capped.
+	huge := io.MultiReader(bytes.NewReader(buf.Bytes()), neverEnding('x'))
+	_, err = cursor.NewImageFromReader(huge, &cursor.ImageOptions{Resize: true})
+	require.ErrorIs(t, err, cursor.ErrImageTooLarge)
+}
+
+func TestImageResizeKeepsTransparency(t *testing.T) {
+	translucent := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	opaque := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	r := rand.New(rand.NewPCG(3, 4))
+	for i := range translucent.Pix {
+		v := uint8(r.IntN(256))
+		translucent.Pix[i], opaque.Pix[i] = v, v
+		if i%4 == 3 {
+			translucent.Pix[i], opaque.Pix[i] = 128, 255
+		}
+	}
+
+	for _, tc := range []struct {
+		img  image.Image
+		want cursor.ImageFormat
+	}{
+		{translucent, cursor.ImagePNG},
+		{opaque, cursor.ImageJPEG},
+	} {
+		img, err := cursor.NewImageFromImage(tc.img, &cursor.ImageOptions{MaxBytes: 2000, Resize: true})
+		require.NoError(t, err)
+		data, err := base64.StdEncoding.DecodeString(img.Data)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(data), 2000)
+		format, err := cursor.DetectImageFormat(data)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, format)
+	}
+}
+
+// neverEnding is an infinite reader of a single byte.
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+// boundedImage gives an image.Uniform finite bounds.
+type boundedImage struct {
+	image.Image
+	bounds image.Rectangle
+}
+
+func (b *boundedImage) Bounds() image.Rectangle { return b.bounds }
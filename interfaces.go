@@ -0,0 +1,111 @@
+package cursor
+
+import (
+	"context"
+)
+
+// AgentsAPI covers the background agent endpoints.
+type AgentsAPI interface {
+	LaunchAgent(ctx context.Context, req LaunchRequest, opts ...CallOption) (*Agent, error)
+	AddFollowup(ctx context.Context, id string, req FollowupRequest, opts ...CallOption) (string, error)
+	GetAgent(ctx context.Context, id string, opts ...CallOption) (*Agent, error)
+	ListAgents(ctx context.Context, limit int, cursor *string, opts ...CallOption) (*ListAgentsResponse, error)
+	DeleteAgent(ctx context.Context, id string, opts ...CallOption) (string, error)
+	GetConversation(ctx context.Context, id string, opts ...CallOption) (*Conversation, error)
+}
+
+// ModelsAPI covers the model listing endpoint.
+type ModelsAPI interface {
+	ListModels(ctx context.Context, opts ...CallOption) (*ListModelsResponse, error)
+}
+
+// RepositoriesAPI covers the GitHub repository listing endpoint.
+type RepositoriesAPI interface {
+	ListRepositories(ctx context.Context, opts ...CallOption) (*ListRepositoriesResponse, error)
+}
+
+// AccountAPI covers the API key metadata endpoint.
+type AccountAPI interface {
+	Me(ctx context.Context, opts ...CallOption) (*MeResponse, error)
+}
+
+// API is the full surface of the Cursor API implemented by Client.
+// Depend on the narrowest interface you need so that it can be mocked or decorated.
+type API interface {
+	AgentsAPI
+	ModelsAPI
+	RepositoriesAPI
+	AccountAPI
+}
+
+var _ API = (*Client)(nil)
+
+// Decorator forwards every API call to Next.
+// Embed it in your own type and override only the methods you want to change,
+// e.g. to add caching, logging or policy checks:
+//
+//	type cachedModels struct {
+//		cursor.Decorator
+//		models *cursor.ListModelsResponse
+//	}
+//
+//	func (c *cachedModels) ListModels(ctx context.Context, opts ...cursor.CallOption) (*cursor.ListModelsResponse, error) {
+//		if c.models == nil {
+//			m, err := c.Decorator.ListModels(ctx, opts...)
+//			if err != nil {
+//				return nil, err
+//			}
+//			c.models = m
+//		}
+//		return c.models, nil
+//	}
+type Decorator struct {
+	Next API
+}
+
+var _ API = Decorator{}
+
+// LaunchAgent calls Next.LaunchAgent.
+func (d Decorator) LaunchAgent(ctx context.Context, req LaunchRequest, opts ...CallOption) (*Agent, error) {
+	return d.Next.LaunchAgent(ctx, req, opts...)
+}
+
+// AddFollowup calls Next.AddFollowup.
+func (d Decorator) AddFollowup(ctx context.Context, id string, req FollowupRequest, opts ...CallOption) (string, error) {
+	return d.Next.AddFollowup(ctx, id, req, opts...)
+}
+
+// GetAgent calls Next.GetAgent.
+func (d Decorator) GetAgent(ctx context.Context, id string, opts ...CallOption) (*Agent, error) {
+	return d.Next.GetAgent(ctx, id, opts...)
+}
+
+// ListAgents calls Next.ListAgents.
+func (d Decorator) ListAgents(ctx context.Context, limit int, cursor *string, opts ...CallOption) (*ListAgentsResponse, error) {
+	return d.Next.ListAgents(ctx, limit, cursor, opts...)
+}
+
+// DeleteAgent calls Next.DeleteAgent.
+func (d Decorator) DeleteAgent(ctx context.Context, id string, opts ...CallOption) (string, error) {
+	return d.Next.DeleteAgent(ctx, id, opts...)
+}
+
+// GetConversation calls Next.GetConversation.
+func (d Decorator) GetConversation(ctx context.Context, id string, opts ...CallOption) (*Conversation, error) {
+	return d.Next.GetConversation(ctx, id, opts...)
+}
+
+// ListModels calls Next.ListModels.
+func (d Decorator) ListModels(ctx context.Context, opts ...CallOption) (*ListModelsResponse, error) {
+	return d.Next.ListModels(ctx, opts...)
+}
+
+// ListRepositories calls Next.ListRepositories.
+func (d Decorator) ListRepositories(ctx context.Context, opts ...CallOption) (*ListRepositoriesResponse, error) {
+	return d.Next.ListRepositories(ctx, opts...)
+}
+
+// Me calls Next.Me.
+func (d Decorator) Me(ctx context.Context, opts ...CallOption) (*MeResponse, error) {
+	return d.Next.Me(ctx, opts...)
+}
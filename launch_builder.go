@@ -0,0 +1,226 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MinWebhookSecretLength is the minimum length of LaunchWebhook.Secret accepted by the API.
+const MinWebhookSecretLength = 32
+
+// ValidationError describes a single problem found by LaunchBuilder.Validate.
+type ValidationError struct {
+	// Field is the JSON path of the invalid field, e.g. "source.repository".
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("cursor: invalid %s: %s", e.Field, e.Message)
+}
+
+// LaunchBuilder builds a LaunchRequest step by step and validates it before
+// it is sent. Methods return the builder for chaining:
+//
+//	req, err := cursor.NewLaunch().
+//		Prompt("Add a README").
+//		Repo("https://github.com/owner/repo").
+//		Branch("docs/readme").
+//		AutoPR().
+//		Build()
+type LaunchBuilder struct {
+	req    LaunchRequest
+	models []string
+}
+
+// NewLaunch starts building a LaunchRequest.
+func NewLaunch() *LaunchBuilder {
+	return &LaunchBuilder{}
+}
+
+// Prompt sets the instructions for the agent, with optional images.
+func (b *LaunchBuilder) Prompt(text string, images ...Image) *LaunchBuilder {
+	b.req.Prompt = Prompt{Text: text, Images: images}
+	return b
+}
+
+// Image attaches an image to the prompt.
+func (b *LaunchBuilder) Image(img Image) *LaunchBuilder {
+	b.req.Prompt.Images = append(b.req.Prompt.Images, img)
+	return b
+}
+
+// Repo sets the GitHub repository URL, e.g. "https://github.com/owner/repo".
+func (b *LaunchBuilder) Repo(repository string) *LaunchBuilder {
+	b.req.Source.Repository = repository
+	return b
+}
+
+// Ref sets the git ref (branch, tag or commit) the agent starts from.
+func (b *LaunchBuilder) Ref(ref string) *LaunchBuilder {
+	b.req.Source.Ref = ref
+	return b
+}
+
+// Branch sets the name of the branch the agent pushes to.
+func (b *LaunchBuilder) Branch(name string) *LaunchBuilder {
+	b.target().BranchName = name
+	return b
+}
+
+// AutoPR makes the agent open a pull request when it finishes.
+func (b *LaunchBuilder) AutoPR() *LaunchBuilder {
+	b.target().AutoCreatePR = true
+	return b
+}
+
+// Model sets the model name; empty uses the default model.
+func (b *LaunchBuilder) Model(name string) *LaunchBuilder {
+	b.req.Model = name
+	return b
+}
+
+// Webhook sets the URL receiving status changes and the secret used to sign them.
+func (b *LaunchBuilder) Webhook(url, secret string) *LaunchBuilder {
+	b.req.Webhook = &LaunchWebhook{URL: url, Secret: secret}
+	return b
+}
+
+// WithModels makes Validate check the model against a cached ListModels result.
+func (b *LaunchBuilder) WithModels(models *ListModelsResponse) *LaunchBuilder {
+	b.models = nil
+	if models != nil {
+		b.models = models.Models
+	}
+	return b
+}
+
+func (b *LaunchBuilder) target() *LaunchTarget {
+	if b.req.Target == nil {
+		b.req.Target = &LaunchTarget{}
+	}
+	return b.req.Target
+}
+
+var githubRepoPattern = regexp.MustCompile(`^https://github\.com/[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?/[A-Za-z0-9._-]+?(?:\.git)?/?$`)
+
+// Validate checks the request without sending it. It returns nil or an error
+// joining a *ValidationError for every problem found.
+func (b *LaunchBuilder) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...any) {
+		errs = append(errs, &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	r := b.req
+
+	if strings.TrimSpace(r.Prompt.Text) == "" {
+		add("prompt.text", "must not be empty")
+	}
+	if len(r.Prompt.Images) > MaxPromptImages {
+		add("prompt.images", "at most %d images are allowed, got %d", MaxPromptImages, len(r.Prompt.Images))
+	}
+	for i, img := range r.Prompt.Images {
+		if img.Data == "" {
+			add(fmt.Sprintf("prompt.images[%d].data", i), "must not be empty")
+		}
+	}
+
+	switch repo := r.Source.Repository; {
+	case repo == "":
+		add("source.repository", "must not be empty")
+	case !githubRepoPattern.MatchString(repo):
+		add("source.repository", "%q is not a GitHub repository URL like https://github.com/owner/repo", repo)
+	}
+	if ref := r.Source.Ref; ref != "" {
+		if msg := checkRefName(ref); msg != "" {
+			add("source.ref", "%q %s", ref, msg)
+		}
+	}
+
+	if t := r.Target; t != nil && t.BranchName != "" {
+		if msg := checkRefName(t.BranchName); msg != "" {
+			add("target.branchName", "%q %s", t.BranchName, msg)
+		}
+	}
+
+	if r.Model != "" && b.models != nil && !slices.Contains(b.models, r.Model) {
+		add("model", "%q is not one of the available models (%s)", r.Model, strings.Join(b.models, ", "))
+	}
+
+	if wh := r.Webhook; wh != nil {
+		u, err := url.Parse(wh.URL)
+		switch {
+		case wh.URL == "":
+			add("webhook.url", "must not be empty")
+		case err != nil || u.Host == "":
+			add("webhook.url", "%q is not a valid URL", wh.URL)
+		case u.Scheme != "https":
+			add("webhook.url", "%q must use https", wh.URL)
+		}
+		if wh.Secret != "" && len(wh.Secret) < MinWebhookSecretLength {
+			add("webhook.secret", "must be at least %d characters", MinWebhookSecretLength)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkRefName applies the git branch naming rules (git check-ref-format) and
+// returns a description of the first violation, or "".
+func checkRefName(name string) string {
+	switch {
+	case name == "@":
+		return `must not be "@"`
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return `must not start or end with "/"`
+	case strings.HasSuffix(name, "."):
+		return `must not end with "."`
+	case strings.HasSuffix(name, ".lock"):
+		return `must not end with ".lock"`
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return `must not contain "..", "//" or "@{"`
+	case strings.ContainsAny(name, " ~^:?*[\\"):
+		return `must not contain spaces or any of ~ ^ : ? * [ \`
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return "must not contain control characters"
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return `must not have a path component starting with "."`
+		}
+	}
+	return ""
+}
+
+// Build validates the request and returns it. On validation failure the
+// request is returned together with the error from Validate.
+func (b *LaunchBuilder) Build() (LaunchRequest, error) {
+	req := b.req
+	req.Prompt.Images = slices.Clone(req.Prompt.Images)
+	if b.req.Target != nil {
+		t := *b.req.Target
+		req.Target = &t
+	}
+	if b.req.Webhook != nil {
+		wh := *b.req.Webhook
+		req.Webhook = &wh
+	}
+	return req, b.Validate()
+}
+
+// Launch validates the request and launches it with api.
+func (b *LaunchBuilder) Launch(ctx context.Context, api AgentsAPI, opts ...CallOption) (*Agent, error) {
+	req, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return api.LaunchAgent(ctx, req, opts...)
+}
@@ -0,0 +1,69 @@
+package cursor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+const testWebhookSecret = "webhook-secret-0123456789abcdef0123"
+
+func TestLaunchBuilder(t *testing.T) {
+	models := &cursor.ListModelsResponse{Models: []string{"claude-4-sonnet", "gpt-5"}}
+	req, err := cursor.NewLaunch().
+		Prompt("Add a README").
+		Repo("https://github.com/octocat/hello-world").
+		Ref("main").
+		Branch("docs/readme").
+		AutoPR().
+		Model("gpt-5").
+		Webhook("https://example.com/hook", testWebhookSecret).
+		WithModels(models).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, cursor.LaunchRequest{
+		Prompt:  cursor.Prompt{Text: "Add a README"},
+		Source:  cursor.Source{Repository: "https://github.com/octocat/hello-world", Ref: "main"},
+		Model:   "gpt-5",
+		Target:  &cursor.LaunchTarget{AutoCreatePR: true, BranchName: "docs/readme"},
+		Webhook: &cursor.LaunchWebhook{URL: "https://example.com/hook", Secret: testWebhookSecret},
+	}, req)
+
+	err = cursor.NewLaunch().
+		Prompt("  ").
+		Repo("github.com/octocat/hello-world").
+		Ref("main..dev").
+		Branch("feature/bad name.lock").
+		Model("gpt-2").
+		WithModels(models).
+		Webhook("http://example.com/hook", "short").
+		Validate()
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr *cursor.ValidationError
+		require.True(t, errors.As(e, &verr))
+		fields = append(fields, verr.Field)
+	}
+	require.Equal(t, []string{"prompt.text", "source.repository", "source.ref", "target.branchName", "model", "webhook.url", "webhook.secret"}, fields)
+
+	for _, repo := range []string{"https://github.com/owner/repo.git", "https://github.com/my-org/my.repo_1/"} {
+		require.NoError(t, cursor.NewLaunch().Prompt("x").Repo(repo).Validate(), repo)
+	}
+	for _, repo := range []string{"https://gitlab.com/owner/repo", "https://github.com/owner", "https://github.com/-owner/repo"} {
+		require.Error(t, cursor.NewLaunch().Prompt("x").Repo(repo).Validate(), repo)
+	}
+
+	srv := cursortest.NewServer()
+	defer srv.Close()
+	agent, err := cursor.NewLaunch().Prompt("Fix tests").Repo("https://github.com/octocat/hello-world").Launch(context.Background(), srv.Client())
+	require.NoError(t, err)
+	require.NotEmpty(t, agent.ID)
+	_, err = cursor.NewLaunch().Launch(context.Background(), srv.Client())
+	require.Error(t, err)
+	require.Len(t, srv.Requests(), 1)
+}
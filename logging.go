@@ -0,0 +1,131 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in logged values.
+const redacted = "[REDACTED]"
+
+// WithLogger logs every API call to logger: operation, method, path, status,
+// latency and number of retries. Successful calls are logged at Info and failed
+// calls at Warn unless changed with WithLogLevels. The API key, webhook secrets
+// and image data are never logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// WithLogLevels sets the levels used by WithLogger for successful and failed calls.
+func WithLogLevels(success, failure slog.Level) Option {
+	return func(c *Client) {
+		c.logLevel = success
+		c.logErrorLevel = failure
+	}
+}
+
+// WithLogBodies makes WithLogger also log request and response bodies, at Debug level.
+func WithLogBodies() Option {
+	return func(c *Client) { c.logBodies = true }
+}
+
+// logMiddleware logs calls made by next.
+func (c *Client) logMiddleware(next Doer) Doer {
+	return DoerFunc(func(ctx context.Context, call *Call) error {
+		start := time.Now()
+		err := next.Do(ctx, call)
+		latency := time.Since(start)
+
+		attrs := []slog.Attr{
+			slog.String("operation", call.Operation),
+			slog.String("method", call.Method),
+			slog.String("path", call.Path),
+			slog.Int("status", call.StatusCode),
+			slog.Duration("latency", latency),
+			slog.Int("retries", max(call.Attempts-1, 0)),
+		}
+		level, msg := c.logLevel, "cursor: call succeeded"
+		if err != nil {
+			level, msg = c.logErrorLevel, "cursor: call failed"
+			errText := c.redactString(err.Error())
+			if key := call.opts.APIKey; key != "" {
+				errText = strings.ReplaceAll(errText, key, redacted)
+			}
+			attrs = append(attrs, slog.String("error", errText))
+		}
+		c.logger.LogAttrs(ctx, level, msg, attrs...)
+
+		if c.logBodies && c.logger.Enabled(ctx, slog.LevelDebug) {
+			body := []slog.Attr{
+				slog.String("operation", call.Operation),
+				slog.String("path", call.Path),
+			}
+			if call.Body != nil {
+				body = append(body, slog.String("request_body", c.redactBody(call.Body)))
+			}
+			if err == nil && call.Out != nil {
+				body = append(body, slog.String("response_body", c.redactBody(call.Out)))
+			}
+			if len(body) > 2 {
+				c.logger.LogAttrs(ctx, slog.LevelDebug, "cursor: call bodies", body...)
+			}
+		}
+		return err
+	})
+}
+
+// redactString removes the API key from s.
+func (c *Client) redactString(s string) string {
+	if c.apiKey != "" {
+		s = strings.ReplaceAll(s, c.apiKey, redacted)
+	}
+	return s
+}
+
+// redactBody encodes v as JSON with secrets, authorization values and image data removed.
+func (c *Client) redactBody(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<unencodable: %v>", err)
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return c.redactString(string(b))
+	}
+	b, _ = json.Marshal(redactValue(generic, ""))
+	return c.redactString(string(b))
+}
+
+// redactValue walks a decoded JSON value; parent is the key holding v.
+func redactValue(v any, parent string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			switch lk := strings.ToLower(k); {
+			case lk == "secret" || lk == "authorization" || lk == "apikey" || lk == "api_key":
+				t[k] = redacted
+			case lk == "data" && parent == "images":
+				if s, ok := val.(string); ok {
+					t[k] = fmt.Sprintf("[%d base64 bytes]", len(s))
+				}
+			default:
+				t[k] = redactValue(val, lk)
+			}
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val, parent)
+		}
+		return t
+	case string:
+		if strings.HasPrefix(t, "Bearer ") {
+			return "Bearer " + redacted
+		}
+	}
+	return v
+}
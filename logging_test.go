@@ -0,0 +1,74 @@
+package cursor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func TestLogger(t *testing.T) {
+	const apiKey = "key_0123456789abcdef"
+	srv := cursortest.NewServer(cursortest.WithAPIKey(apiKey))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := srv.Client(
+		cursor.WithLogger(logger),
+		cursor.WithLogBodies(),
+		cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+	)
+
+	imageData := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xff}, 300))
+	agent := launch(t, c, cursor.LaunchRequest{
+		Prompt:  cursor.Prompt{Text: "Fix the layout", Images: []cursor.Image{{Data: imageData}}},
+		Webhook: &cursor.LaunchWebhook{URL: "https://example.com/hook", Secret: testWebhookSecret},
+	})
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents/*", StatusCode: http.StatusBadGateway, Times: 1})
+	_, err := c.GetAgent(context.Background(), agent.ID)
+	require.NoError(t, err)
+	_, err = c.GetAgent(context.Background(), "missing")
+	require.Error(t, err)
+
+	out := buf.String()
+	require.NotContains(t, out, apiKey)
+	require.NotContains(t, out, testWebhookSecret)
+	require.NotContains(t, out, imageData)
+	require.Contains(t, out, "[400 base64 bytes]")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 5) // one record per call, plus bodies for the two successful calls
+	launchRec := records[0]
+	require.Equal(t, "INFO", launchRec["level"])
+	require.Equal(t, cursor.OpLaunchAgent, launchRec["operation"])
+	require.Equal(t, "/v0/agents", launchRec["path"])
+	require.EqualValues(t, 200, launchRec["status"])
+	require.Contains(t, launchRec, "latency")
+	require.Equal(t, "DEBUG", records[1]["level"])
+	require.Contains(t, records[1]["request_body"], `"secret":"[REDACTED]"`)
+	require.Contains(t, records[1]["response_body"], agent.ID)
+
+	require.Equal(t, cursor.OpGetAgent, records[2]["operation"])
+	require.EqualValues(t, 1, records[2]["retries"])
+
+	failed := records[4]
+	require.Equal(t, "WARN", failed["level"])
+	require.EqualValues(t, 404, failed["status"])
+	require.Contains(t, failed, "error")
+}
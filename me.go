@@ -5,9 +5,9 @@ import (
 )
 
 // Me returns metadata about the current API key.
-func (c *Client) Me(ctx context.Context) (*MeResponse, error) {
+func (c *Client) Me(ctx context.Context, opts ...CallOption) (*MeResponse, error) {
 	var out MeResponse
-	if err := c.do(ctx, "GET", "/v0/me", nil, nil, &out); err != nil {
+	if err := c.do(ctx, OpMe, "GET", "/v0/me", nil, nil, &out, opts); err != nil {
 		return nil, err
 	}
 	return &out, nil
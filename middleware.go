@@ -0,0 +1,85 @@
+package cursor
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Operation names reported in Call.Operation, matching the Client methods.
+const (
+	OpLaunchAgent      = "LaunchAgent"
+	OpAddFollowup      = "AddFollowup"
+	OpGetAgent         = "GetAgent"
+	OpListAgents       = "ListAgents"
+	OpDeleteAgent      = "DeleteAgent"
+	OpGetConversation  = "GetConversation"
+	OpListModels       = "ListModels"
+	OpListRepositories = "ListRepositories"
+	OpMe               = "Me"
+)
+
+// Call describes a single SDK operation as it passes through the middleware chain.
+// Middleware may inspect and modify the request fields before calling the next
+// Doer, and inspect the result fields afterwards.
+type Call struct {
+	// Operation is the name of the Client method, e.g. OpLaunchAgent.
+	Operation string
+	Method    string
+	// Path is the API path relative to the base URL, e.g. "/v0/agents/bc_123".
+	Path  string
+	Query url.Values
+	// Header holds extra request headers sent with every attempt.
+	Header http.Header
+	// Body is the typed request value (e.g. LaunchRequest), or nil.
+	Body any
+	// Out points to the typed response value the JSON response is decoded into,
+	// or is nil. It is populated when the call succeeds.
+	Out any
+
+	// StatusCode is the HTTP status of the last attempt, or 0 if no response was received.
+	StatusCode int
+	// Attempts is the number of HTTP requests made, including retries.
+	Attempts int
+	// Response describes the last HTTP response, or is nil if none was received.
+	// A failed call's *APIError refers to the same value in its Meta field.
+	Response *ResponseMeta
+
+	// opts holds the CallOptions applied by the transport.
+	opts CallOptions
+}
+
+// Doer performs a Call.
+type Doer interface {
+	Do(ctx context.Context, call *Call) error
+}
+
+// DoerFunc adapts a function to the Doer interface.
+type DoerFunc func(ctx context.Context, call *Call) error
+
+// Do implements Doer.
+func (f DoerFunc) Do(ctx context.Context, call *Call) error { return f(ctx, call) }
+
+// Middleware wraps a Doer, e.g. for logging, metrics, policy checks or caching.
+// It runs once per operation, around retries and rate limiting. A middleware
+// may return without calling next, e.g. to serve call.Out from a cache.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middleware around every API call. Middleware added first
+// is outermost.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) { c.middleware = append(c.middleware, mw...) }
+}
+
+// chain wraps the client's transport in its middleware. Logging is innermost,
+// so it records what was actually sent.
+func (c *Client) chain() Doer {
+	var d Doer = DoerFunc(c.execute)
+	if c.logger != nil {
+		d = c.logMiddleware(d)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		d = c.middleware[i](d)
+	}
+	return d
+}
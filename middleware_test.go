@@ -0,0 +1,102 @@
+package cursor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+)
+
+func launch(t *testing.T, c *cursor.Client, req cursor.LaunchRequest) *cursor.Agent {
+	t.Helper()
+	if req.Prompt.Text == "" {
+		req.Prompt.Text = "Add a README"
+	}
+	if req.Source.Repository == "" {
+		req.Source.Repository = "https://github.com/octocat/hello-world"
+	}
+	agent, err := c.LaunchAgent(context.Background(), req)
+	require.NoError(t, err)
+	return agent
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+
+	var order []string
+	var calls []cursor.Call
+	record := func(next cursor.Doer) cursor.Doer {
+		return cursor.DoerFunc(func(ctx context.Context, call *cursor.Call) error {
+			order = append(order, "record")
+			err := next.Do(ctx, call)
+			calls = append(calls, *call)
+			return err
+		})
+	}
+	errForbiddenRepo := errors.New("repository not allowed")
+	policy := func(next cursor.Doer) cursor.Doer {
+		return cursor.DoerFunc(func(ctx context.Context, call *cursor.Call) error {
+			order = append(order, "policy")
+			if req, ok := call.Body.(cursor.LaunchRequest); ok && req.Source.Repository == "https://github.com/octocat/secret" {
+				return errForbiddenRepo
+			}
+			if call.Header == nil {
+				call.Header = http.Header{}
+			}
+			call.Header.Set("X-Team", "platform")
+			return next.Do(ctx, call)
+		})
+	}
+	cached := &cursor.ListModelsResponse{Models: []string{"cached-model"}}
+	cache := func(next cursor.Doer) cursor.Doer {
+		return cursor.DoerFunc(func(ctx context.Context, call *cursor.Call) error {
+			if call.Operation == cursor.OpListModels {
+				*call.Out.(*cursor.ListModelsResponse) = *cached
+				return nil
+			}
+			return next.Do(ctx, call)
+		})
+	}
+
+	c := srv.Client(
+		cursor.WithMiddleware(record, policy),
+		cursor.WithMiddleware(cache),
+		cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+	)
+	agent := launch(t, c, cursor.LaunchRequest{})
+	require.Equal(t, []string{"record", "policy"}, order)
+	require.Equal(t, cursor.OpLaunchAgent, calls[0].Operation)
+	require.Equal(t, "POST", calls[0].Method)
+	require.Equal(t, "/v0/agents", calls[0].Path)
+	require.Equal(t, agent, calls[0].Out)
+	require.Equal(t, http.StatusOK, calls[0].StatusCode)
+	require.Equal(t, 1, calls[0].Attempts)
+	require.Equal(t, "platform", calls[0].Header.Get("X-Team"))
+
+	_, err := c.LaunchAgent(context.Background(), cursor.LaunchRequest{Source: cursor.Source{Repository: "https://github.com/octocat/secret"}})
+	require.ErrorIs(t, err, errForbiddenRepo)
+	require.Len(t, srv.Requests(), 1)
+
+	models, err := c.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, cached, models)
+	require.Len(t, srv.Requests(), 1)
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents/*", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, err = c.GetAgent(context.Background(), agent.ID)
+	require.NoError(t, err)
+	last := calls[len(calls)-1]
+	require.Equal(t, cursor.OpGetAgent, last.Operation)
+	require.Equal(t, 2, last.Attempts)
+
+	_, err = c.GetAgent(context.Background(), "missing")
+	require.ErrorIs(t, err, cursor.ErrNotFound)
+	require.Equal(t, http.StatusNotFound, calls[len(calls)-1].StatusCode)
+}
@@ -5,9 +5,9 @@ import (
 )
 
 // ListModels retrieves available model names.
-func (c *Client) ListModels(ctx context.Context) (*ListModelsResponse, error) {
+func (c *Client) ListModels(ctx context.Context, opts ...CallOption) (*ListModelsResponse, error) {
 	var out ListModelsResponse
-	if err := c.do(ctx, "GET", "/v0/models", nil, nil, &out); err != nil {
+	if err := c.do(ctx, OpListModels, "GET", "/v0/models", nil, nil, &out, opts); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -0,0 +1,228 @@
+// Package otelcursor instruments a cursor.Client with OpenTelemetry.
+//
+// Middleware creates a client span per SDK operation, records call latency,
+// error and rate-limit metrics, and injects the trace context into request
+// headers:
+//
+//	c := cursor.New(apiKey, cursor.WithMiddleware(otelcursor.Middleware()))
+//
+// The global TracerProvider, MeterProvider and TextMapPropagator are used
+// unless overridden with options.
+package otelcursor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter.
+const ScopeName = "github.com/unkn0wncode/cursor-go-sdk/otelcursor"
+
+// Attribute keys set on spans and metrics.
+const (
+	AttrOperation   = attribute.Key("cursor.operation")
+	AttrAgentID     = attribute.Key("cursor.agent.id")
+	AttrAgentStatus = attribute.Key("cursor.agent.status")
+	AttrRepository  = attribute.Key("cursor.repository")
+	AttrModel       = attribute.Key("cursor.model")
+	AttrAttempts    = attribute.Key("cursor.attempts")
+	AttrMethod      = attribute.Key("http.request.method")
+	AttrStatusCode  = attribute.Key("http.response.status_code")
+	AttrErrorType   = attribute.Key("error.type")
+)
+
+// Metric names.
+const (
+	MetricDuration    = "cursor.client.operation.duration"
+	MetricErrors      = "cursor.client.errors"
+	MetricRateLimited = "cursor.client.rate_limited"
+)
+
+// Option configures Middleware.
+type Option func(*config)
+
+type config struct {
+	tp          trace.TracerProvider
+	mp          metric.MeterProvider
+	propagators propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the TracerProvider used to create spans.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tp = tp }
+}
+
+// WithMeterProvider sets the MeterProvider used to record metrics.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.mp = mp }
+}
+
+// WithPropagators sets the propagators used to inject the trace context into requests.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) { c.propagators = p }
+}
+
+type instruments struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+	duration    metric.Float64Histogram
+	errors      metric.Int64Counter
+	rateLimited metric.Int64Counter
+}
+
+// Middleware returns a cursor.Middleware that traces and measures every call.
+// Instruments that fail to register are replaced by no-ops.
+func Middleware(opts ...Option) cursor.Middleware {
+	cfg := config{
+		tp:          otel.GetTracerProvider(),
+		mp:          otel.GetMeterProvider(),
+		propagators: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.mp.Meter(ScopeName)
+	in := &instruments{
+		tracer:      cfg.tp.Tracer(ScopeName),
+		propagators: cfg.propagators,
+	}
+	var err error
+	if in.duration, err = meter.Float64Histogram(MetricDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of Cursor API operations, including retries.")); err != nil {
+		otel.Handle(err)
+	}
+	if in.errors, err = meter.Int64Counter(MetricErrors,
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of failed Cursor API operations.")); err != nil {
+		otel.Handle(err)
+	}
+	if in.rateLimited, err = meter.Int64Counter(MetricRateLimited,
+		metric.WithUnit("{operation}"),
+		metric.WithDescription("Number of Cursor API operations rejected by rate limits.")); err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next cursor.Doer) cursor.Doer {
+		return cursor.DoerFunc(func(ctx context.Context, call *cursor.Call) error {
+			return in.do(ctx, call, next)
+		})
+	}
+}
+
+func (in *instruments) do(ctx context.Context, call *cursor.Call, next cursor.Doer) error {
+	ctx, span := in.tracer.Start(ctx, "cursor."+call.Operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(requestAttributes(call)...),
+	)
+	defer span.End()
+
+	header := make(http.Header, len(call.Header)+2)
+	for k, v := range call.Header {
+		header[k] = v
+	}
+	in.propagators.Inject(ctx, propagation.HeaderCarrier(header))
+	call.Header = header
+
+	start := time.Now()
+	err := next.Do(ctx, call)
+	elapsed := time.Since(start)
+
+	span.SetAttributes(responseAttributes(call)...)
+	metricAttrs := []attribute.KeyValue{AttrOperation.String(call.Operation), AttrMethod.String(call.Method)}
+	if call.StatusCode != 0 {
+		metricAttrs = append(metricAttrs, AttrStatusCode.Int(call.StatusCode))
+	}
+	if err != nil {
+		errType := errorType(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(AttrErrorType.String(errType))
+		metricAttrs = append(metricAttrs, AttrErrorType.String(errType))
+		if in.errors != nil {
+			in.errors.Add(ctx, 1, metric.WithAttributes(metricAttrs...))
+		}
+		if errors.Is(err, cursor.ErrRateLimited) && in.rateLimited != nil {
+			in.rateLimited.Add(ctx, 1, metric.WithAttributes(AttrOperation.String(call.Operation)))
+		}
+	}
+	if in.duration != nil {
+		in.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(metricAttrs...))
+	}
+	return err
+}
+
+// requestAttributes describes the call before it is sent.
+func requestAttributes(call *cursor.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		AttrOperation.String(call.Operation),
+		AttrMethod.String(call.Method),
+	}
+	if id := agentIDFromPath(call.Path); id != "" {
+		attrs = append(attrs, AttrAgentID.String(id))
+	}
+	if req, ok := call.Body.(cursor.LaunchRequest); ok {
+		attrs = append(attrs, AttrRepository.String(req.Source.Repository))
+		if req.Model != "" {
+			attrs = append(attrs, AttrModel.String(req.Model))
+		}
+	}
+	return attrs
+}
+
+// responseAttributes describes the outcome of the call.
+func responseAttributes(call *cursor.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttrAttempts.Int(call.Attempts)}
+	if call.StatusCode != 0 {
+		attrs = append(attrs, AttrStatusCode.Int(call.StatusCode))
+	}
+	if a, ok := call.Out.(*cursor.Agent); ok && a.ID != "" {
+		attrs = append(attrs, AttrAgentID.String(a.ID), AttrAgentStatus.String(string(a.Status)))
+		if a.Source.Repository != "" {
+			attrs = append(attrs, AttrRepository.String(a.Source.Repository))
+		}
+	}
+	return attrs
+}
+
+// agentIDFromPath extracts the agent ID from paths like /v0/agents/{id}[/...].
+func agentIDFromPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/v0/agents/")
+	if !ok {
+		return ""
+	}
+	id, _, _ := strings.Cut(rest, "/")
+	return id
+}
+
+// errorType classifies err for the error.type attribute.
+func errorType(err error) string {
+	var apiErr *cursor.APIError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, cursor.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "context"
+	case errors.As(err, &netErr):
+		return "network"
+	}
+	return "other"
+}
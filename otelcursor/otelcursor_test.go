@@ -0,0 +1,151 @@
+package otelcursor_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+	"github.com/unkn0wncode/cursor-go-sdk/otelcursor"
+)
+
+// headerRecorder records the traceparent header of every request.
+type headerRecorder struct {
+	mu          sync.Mutex
+	traceparent []string
+}
+
+func (h *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.traceparent = append(h.traceparent, req.Header.Get("traceparent"))
+	h.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	headers := &headerRecorder{}
+	c := srv.Client(
+		cursor.WithHTTPClient(&http.Client{Transport: headers}),
+		cursor.WithMiddleware(otelcursor.Middleware(
+			otelcursor.WithTracerProvider(tp),
+			otelcursor.WithMeterProvider(mp),
+			otelcursor.WithPropagators(propagation.TraceContext{}),
+		)),
+	)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	agent, err := c.LaunchAgent(ctx, cursor.LaunchRequest{
+		Prompt: cursor.Prompt{Text: "Add a README"},
+		Source: cursor.Source{Repository: "https://github.com/octocat/hello-world"},
+		Model:  "gpt-5",
+	})
+	require.NoError(t, err)
+	srv.InjectFault(cursortest.Fault{Path: "/v0/agents/*", StatusCode: http.StatusTooManyRequests, Times: 1})
+	_, err = c.GetAgent(ctx, agent.ID)
+	require.ErrorIs(t, err, cursor.ErrRateLimited)
+	parent.End()
+
+	ended := spans.Ended()
+	require.Len(t, ended, 3)
+	launch, get := ended[0], ended[1]
+
+	require.Equal(t, "cursor.LaunchAgent", launch.Name())
+	require.Equal(t, trace.SpanKindClient, launch.SpanKind())
+	require.Equal(t, parent.SpanContext().SpanID(), launch.Parent().SpanID())
+	attrs := attribute.NewSet(launch.Attributes()...)
+	for key, want := range map[attribute.Key]attribute.Value{
+		otelcursor.AttrOperation:  attribute.StringValue(cursor.OpLaunchAgent),
+		otelcursor.AttrAgentID:    attribute.StringValue(agent.ID),
+		otelcursor.AttrRepository: attribute.StringValue("https://github.com/octocat/hello-world"),
+		otelcursor.AttrModel:      attribute.StringValue("gpt-5"),
+		otelcursor.AttrStatusCode: attribute.IntValue(http.StatusOK),
+	} {
+		got, ok := attrs.Value(key)
+		require.True(t, ok, key)
+		require.Equal(t, want, got, key)
+	}
+	require.Equal(t, codes.Unset, launch.Status().Code)
+
+	require.Equal(t, "cursor.GetAgent", get.Name())
+	require.Equal(t, codes.Error, get.Status().Code)
+	getAttrs := attribute.NewSet(get.Attributes()...)
+	id, _ := getAttrs.Value(otelcursor.AttrAgentID)
+	require.Equal(t, agent.ID, id.AsString())
+	status, _ := getAttrs.Value(otelcursor.AttrStatusCode)
+	require.Equal(t, int64(http.StatusTooManyRequests), status.AsInt64())
+	require.Len(t, get.Events(), 1)
+	require.Equal(t, "exception", get.Events()[0].Name)
+
+	// Each request carries the context of its operation's span.
+	require.Len(t, headers.traceparent, 2)
+	require.Contains(t, headers.traceparent[0], launch.SpanContext().SpanID().String())
+	require.Contains(t, headers.traceparent[1], get.SpanContext().SpanID().String())
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	byName := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		byName[m.Name] = m.Data
+	}
+
+	duration, ok := byName[otelcursor.MetricDuration].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	var count uint64
+	for _, dp := range duration.DataPoints {
+		count += dp.Count
+	}
+	require.Equal(t, uint64(2), count)
+
+	for _, name := range []string{otelcursor.MetricErrors, otelcursor.MetricRateLimited} {
+		sum, ok := byName[name].(metricdata.Sum[int64])
+		require.True(t, ok, name)
+		require.Len(t, sum.DataPoints, 1, name)
+		require.Equal(t, int64(1), sum.DataPoints[0].Value, name)
+		op, _ := sum.DataPoints[0].Attributes.Value(otelcursor.AttrOperation)
+		require.Equal(t, cursor.OpGetAgent, op.AsString(), name)
+	}
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	srv := cursortest.NewServer()
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	c := srv.Client(
+		cursor.WithRetry(cursor.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+		cursor.WithMiddleware(otelcursor.Middleware(otelcursor.WithTracerProvider(tp))),
+	)
+
+	srv.InjectFault(cursortest.Fault{Path: "/v0/models", StatusCode: http.StatusBadGateway, Times: 1})
+	_, err := c.ListModels(context.Background())
+	require.NoError(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1) // one span per operation, not per attempt
+	set := attribute.NewSet(ended[0].Attributes()...)
+	attempts, _ := set.Value(otelcursor.AttrAttempts)
+	require.Equal(t, int64(2), attempts.AsInt64())
+}
@@ -0,0 +1,69 @@
+package cursor
+
+import (
+	"context"
+	"iter"
+)
+
+// ListAgentsOptions configures Agents and AllAgents. The zero value walks every agent.
+type ListAgentsOptions struct {
+	// PageSize is the limit sent with every ListAgents request; 0 uses the server default.
+	PageSize int
+	// Cursor resumes listing from a NextCursor returned earlier.
+	Cursor string
+	// MaxItems stops iteration after that many agents; 0 means no cap.
+	MaxItems int
+}
+
+// Agents returns an iterator over all agents, fetching pages lazily via ListAgents.
+// Breaking out of the loop stops fetching further pages.
+// If a request fails, the error is yielded once and iteration ends. opts may be nil;
+// callOpts apply to every ListAgents request.
+func (c *Client) Agents(ctx context.Context, opts *ListAgentsOptions, callOpts ...CallOption) iter.Seq2[Agent, error] {
+	var o ListAgentsOptions
+	if opts != nil {
+		o = *opts
+	}
+	return func(yield func(Agent, error) bool) {
+		cursor := o.Cursor
+		seen := 0
+		for {
+			var cur *string
+			if cursor != "" {
+				cur = &cursor
+			}
+			page, err := c.ListAgents(ctx, o.PageSize, cur, callOpts...)
+			if err != nil {
+				yield(Agent{}, err)
+				return
+			}
+			for _, a := range page.Agents {
+				if !yield(a, nil) {
+					return
+				}
+				seen++
+				if o.MaxItems > 0 && seen >= o.MaxItems {
+					return
+				}
+			}
+			// Stop on the last page, and guard against a server repeating the same cursor.
+			if page.NextCursor == nil || *page.NextCursor == "" || *page.NextCursor == cursor || len(page.Agents) == 0 {
+				return
+			}
+			cursor = *page.NextCursor
+		}
+	}
+}
+
+// AllAgents collects the agents produced by Agents into a slice.
+// On error it returns the agents collected so far together with the error.
+func (c *Client) AllAgents(ctx context.Context, opts *ListAgentsOptions, callOpts ...CallOption) ([]Agent, error) {
+	var out []Agent
+	for a, err := range c.Agents(ctx, opts, callOpts...) {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
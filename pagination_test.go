@@ -0,0 +1,119 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pageServer serves GET /v0/agents from total agents in pages of the requested
+// limit. The cursor is the index of the next agent. fail makes the request for
+// that cursor return 500; repeat makes the page at that cursor return its own cursor.
+type pageServer struct {
+	total  int
+	fail   string
+	repeat string
+
+	mu      sync.Mutex
+	cursors []string
+}
+
+func (p *pageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cur := r.URL.Query().Get("cursor")
+	p.mu.Lock()
+	p.cursors = append(p.cursors, cur)
+	p.mu.Unlock()
+	if cur == p.fail && p.fail != "" {
+		http.Error(w, `{"error":{"message":"boom"}}`, http.StatusInternalServerError)
+		return
+	}
+	start, _ := strconv.Atoi(cur)
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	var resp ListAgentsResponse
+	for i := start; i < min(start+limit, p.total); i++ {
+		resp.Agents = append(resp.Agents, Agent{ID: fmt.Sprintf("bc_%d", i)})
+	}
+	if next := start + limit; next < p.total {
+		s := strconv.Itoa(next)
+		if cur == p.repeat && p.repeat != "" {
+			s = cur
+		}
+		resp.NextCursor = &s
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func pageClient(t *testing.T, p *pageServer) *Client {
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return New("key", WithBaseURL(srv.URL))
+}
+
+func ids(agents []Agent) []string {
+	var out []string
+	for _, a := range agents {
+		out = append(out, a.ID)
+	}
+	return out
+}
+
+func TestAgentsPagination(t *testing.T) {
+	ctx := context.Background()
+	p := &pageServer{total: 7}
+	c := pageClient(t, p)
+
+	all, err := c.AllAgents(ctx, &ListAgentsOptions{PageSize: 3})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bc_0", "bc_1", "bc_2", "bc_3", "bc_4", "bc_5", "bc_6"}, ids(all))
+	require.Equal(t, []string{"", "3", "6"}, p.cursors)
+
+	// Resuming from a cursor and capping the number of items.
+	p.cursors = nil
+	some, err := c.AllAgents(ctx, &ListAgentsOptions{PageSize: 3, Cursor: "3", MaxItems: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bc_3", "bc_4"}, ids(some))
+	require.Equal(t, []string{"3"}, p.cursors)
+
+	// Breaking out of the loop fetches no further pages.
+	p.cursors = nil
+	for range c.Agents(ctx, &ListAgentsOptions{PageSize: 3}) {
+		break
+	}
+	require.Len(t, p.cursors, 1)
+}
+
+func TestAgentsPaginationStops(t *testing.T) {
+	ctx := context.Background()
+
+	// A failing page yields the error once and returns what was collected.
+	c := pageClient(t, &pageServer{total: 7, fail: "3"})
+	var errs int
+	var got []Agent
+	for a, err := range c.Agents(ctx, &ListAgentsOptions{PageSize: 3}) {
+		if err != nil {
+			errs++
+			continue
+		}
+		got = append(got, a)
+	}
+	require.Equal(t, 1, errs)
+	require.Len(t, got, 3)
+	partial, err := c.AllAgents(ctx, &ListAgentsOptions{PageSize: 3})
+	require.ErrorIs(t, err, ErrServer)
+	require.Len(t, partial, 3)
+
+	// A server repeating a cursor does not cause an endless loop.
+	p := &pageServer{total: 7, repeat: "3"}
+	c = pageClient(t, p)
+	all, err := c.AllAgents(ctx, &ListAgentsOptions{PageSize: 3})
+	require.NoError(t, err)
+	require.Len(t, all, 6)
+	require.Equal(t, []string{"", "3"}, p.cursors)
+}
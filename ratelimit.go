@@ -0,0 +1,162 @@
+package cursor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit allows Requests requests per Per interval.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// DefaultRateLimits returns the documented per-endpoint limits of the Cursor API, keyed by path.
+// Every call returns a new map, so callers may modify it.
+func DefaultRateLimits() map[string][]RateLimit {
+	return map[string][]RateLimit{
+		"/v0/repositories": {
+			{Requests: 1, Per: time.Minute},
+			{Requests: 30, Per: time.Hour},
+		},
+	}
+}
+
+// RateLimitMode controls what happens when a request would exceed a client-side rate limit.
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks until a token is available or the context is done.
+	RateLimitWait RateLimitMode = iota
+	// RateLimitFailFast returns a *RateLimitExceededError without waiting.
+	RateLimitFailFast
+)
+
+// RateLimitExceededError is returned when a request is rejected by the client-side rate limiter.
+type RateLimitExceededError struct {
+	Path       string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitExceededError) Error() string {
+	return fmt.Sprintf("client rate limit exceeded for %s: retry after %s", e.Path, e.RetryAfter)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitExceededError) Is(target error) bool { return target == ErrRateLimited }
+
+// RateLimiter enforces token-bucket rate limits per endpoint path.
+// A single RateLimiter may be shared by several clients using the same API key.
+type RateLimiter struct {
+	mode    RateLimitMode
+	mu      sync.Mutex
+	buckets map[string][]*tokenBucket
+	now     func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter enforcing limits keyed by endpoint path.
+// Paths without limits are not restricted.
+func NewRateLimiter(mode RateLimitMode, limits map[string][]RateLimit) *RateLimiter {
+	l := &RateLimiter{
+		mode:    mode,
+		buckets: make(map[string][]*tokenBucket, len(limits)),
+		now:     time.Now,
+	}
+	for path, ls := range limits {
+		for _, rl := range ls {
+			if rl.Requests <= 0 || rl.Per <= 0 {
+				continue
+			}
+			l.buckets[path] = append(l.buckets[path], &tokenBucket{
+				capacity: float64(rl.Requests),
+				tokens:   float64(rl.Requests),
+				rate:     float64(rl.Requests) / rl.Per.Seconds(),
+			})
+		}
+	}
+	return l
+}
+
+// WithRateLimiter enables client-side rate limiting using l.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) { c.limiter = l }
+}
+
+// WithDefaultRateLimits enables client-side rate limiting using DefaultRateLimits.
+func WithDefaultRateLimits(mode RateLimitMode) Option {
+	return WithRateLimiter(NewRateLimiter(mode, DefaultRateLimits()))
+}
+
+// Wait takes a token for path, blocking or failing according to the limiter mode.
+// In RateLimitWait mode it fails early with *RateLimitExceededError if the wait
+// would outlast the context deadline.
+func (l *RateLimiter) Wait(ctx context.Context, path string) error {
+	for {
+		wait := l.reserve(path)
+		if wait == 0 {
+			return nil
+		}
+		if l.mode == RateLimitFailFast {
+			return &RateLimitExceededError{Path: path, RetryAfter: wait}
+		}
+		if deadline, ok := ctx.Deadline(); ok && l.now().Add(wait).After(deadline) {
+			return &RateLimitExceededError{Path: path, RetryAfter: wait}
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token from every bucket for path if all have one available.
+// Otherwise it takes nothing and returns the time until all buckets can serve a request.
+func (l *RateLimiter) reserve(path string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	buckets := l.buckets[path]
+	now := l.now()
+	var wait time.Duration
+	for _, b := range buckets {
+		b.refill(now)
+		if d := b.wait(); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// tokenBucket is a single token bucket. It is guarded by RateLimiter.mu.
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// wait returns the time until a whole token is available.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	d := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return d
+}
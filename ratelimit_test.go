@@ -0,0 +1,81 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(mode RateLimitMode, limits map[string][]RateLimit) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Now()}
+	l := NewRateLimiter(mode, limits)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l, clock := newTestLimiter(RateLimitFailFast, map[string][]RateLimit{
+		"/v0/models": {{Requests: 2, Per: time.Second}, {Requests: 3, Per: time.Minute}},
+	})
+
+	require.Zero(t, l.reserve("/v0/models"))
+	require.Zero(t, l.reserve("/v0/models"))
+	require.Equal(t, 500*time.Millisecond, l.reserve("/v0/models"))
+	clock.advance(250 * time.Millisecond)
+	require.Equal(t, 250*time.Millisecond, l.reserve("/v0/models"))
+	clock.advance(250 * time.Millisecond)
+	require.Zero(t, l.reserve("/v0/models"))
+
+	// The per-minute bucket (one token per 20s) has refilled for 1.5s since it
+	// was emptied; the longest wait wins.
+	clock.advance(time.Second)
+	require.Equal(t, 18500*time.Millisecond, l.reserve("/v0/models"))
+
+	// Buckets never fill beyond their capacity.
+	clock.advance(time.Hour)
+	for range 2 {
+		require.Zero(t, l.reserve("/v0/models"))
+	}
+	require.Positive(t, l.reserve("/v0/models"))
+
+	// Paths without limits are not restricted.
+	for range 10 {
+		require.Zero(t, l.reserve("/v0/me"))
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limits := map[string][]RateLimit{"/v0/repositories": {{Requests: 1, Per: time.Minute}}}
+
+	l, _ := newTestLimiter(RateLimitFailFast, limits)
+	require.NoError(t, l.Wait(context.Background(), "/v0/repositories"))
+	err := l.Wait(context.Background(), "/v0/repositories")
+	var rlErr *RateLimitExceededError
+	require.ErrorAs(t, err, &rlErr)
+	require.Equal(t, time.Minute, rlErr.RetryAfter)
+	require.True(t, errors.Is(err, ErrRateLimited))
+
+	// In wait mode, a wait that would outlast the deadline fails immediately.
+	l, clock := newTestLimiter(RateLimitWait, limits)
+	require.NoError(t, l.Wait(context.Background(), "/v0/repositories"))
+	ctx, cancel := context.WithDeadline(context.Background(), clock.now().Add(30*time.Second))
+	defer cancel()
+	require.ErrorAs(t, l.Wait(ctx, "/v0/repositories"), &rlErr)
+	require.Equal(t, time.Minute, rlErr.RetryAfter)
+}
+
+func TestDefaultRateLimitsIsACopy(t *testing.T) {
+	limits := DefaultRateLimits()
+	limits["/v0/repositories"][0].Requests = 1000
+	delete(limits, "/v0/repositories")
+	require.Equal(t, RateLimit{Requests: 1, Per: time.Minute}, DefaultRateLimits()["/v0/repositories"][0])
+}
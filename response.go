@@ -0,0 +1,85 @@
+package cursor
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response headers parsed into ResponseMeta.
+const (
+	HeaderRequestID          = "X-Request-Id"
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// ResponseMeta describes the HTTP response to an API call. Capture it with
+// WithResponseMeta, or read it from APIError.Meta when a call fails.
+type ResponseMeta struct {
+	// StatusCode is the HTTP status of the last attempt.
+	StatusCode int
+	// Header holds the headers of the last response.
+	Header http.Header
+	// RequestID is the server-assigned request ID; include it when contacting support.
+	RequestID string
+	// RateLimit is parsed from the X-RateLimit-* headers, or nil if none were sent.
+	RateLimit *RateLimitStatus
+	// Latency is the duration of the whole call, including retries and backoff.
+	Latency time.Duration
+	// Attempts is the number of HTTP requests made, including retries.
+	Attempts int
+}
+
+// RateLimitStatus is the rate-limit state reported by the server.
+type RateLimitStatus struct {
+	// Limit and Remaining are the request quota and what is left of it, or -1 if not reported.
+	Limit     int
+	Remaining int
+	// Reset is when the quota is replenished, or zero if not reported.
+	Reset time.Time
+}
+
+// WithResponseMeta stores the metadata of the last HTTP response in meta when
+// the call returns, whether it succeeded or not. meta is zeroed if no
+// response was received.
+func WithResponseMeta(meta *ResponseMeta) CallOption {
+	return func(o *CallOptions) { o.Meta = meta }
+}
+
+// newResponseMeta reads the metadata of a single response.
+func newResponseMeta(resp *http.Response) *ResponseMeta {
+	return &ResponseMeta{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		RequestID:  resp.Header.Get(HeaderRequestID),
+		RateLimit:  parseRateLimit(resp.Header, time.Now()),
+	}
+}
+
+// parseRateLimit parses the X-RateLimit-* headers. Reset is accepted either as
+// a Unix timestamp or as a number of seconds from now.
+func parseRateLimit(h http.Header, now time.Time) *RateLimitStatus {
+	limit, remaining, reset := h.Get(HeaderRateLimitLimit), h.Get(HeaderRateLimitRemaining), h.Get(HeaderRateLimitReset)
+	if limit == "" && remaining == "" && reset == "" {
+		return nil
+	}
+	rl := &RateLimitStatus{Limit: -1, Remaining: -1}
+	if n, err := strconv.Atoi(limit); err == nil {
+		rl.Limit = n
+	}
+	if n, err := strconv.Atoi(remaining); err == nil {
+		rl.Remaining = n
+	}
+	if secs, err := strconv.ParseInt(reset, 10, 64); err == nil && secs >= 0 {
+		// Values this large can only be timestamps: 1e9 seconds is over 30 years.
+		if secs >= 1e9 {
+			rl.Reset = time.Unix(secs, 0)
+		} else {
+			rl.Reset = now.Add(time.Duration(secs) * time.Second)
+		}
+	} else if t, err := http.ParseTime(reset); err == nil {
+		rl.Reset = t
+	}
+	return rl
+}
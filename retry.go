@@ -0,0 +1,127 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures automatic retries performed by the Client.
+//
+// 429 responses are retried for every request. Transport errors and 5xx
+// responses are retried only for idempotent methods (GET, DELETE) and
+// requests with an Idempotency-Key header (see WithIdempotencyKey), since the
+// server may already have processed a POST whose response was lost.
+// A Retry-After header on the response takes precedence over the computed backoff.
+// Retries never outlive the caller's context: if the next attempt would start after
+// the context deadline, the last error is returned immediately.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed delay between attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every attempt. Values below 1 are treated as 1.
+	Multiplier float64
+	// Jitter is the fraction (0..1) of each delay that is randomized.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most workloads.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetry enables automatic retries using the given policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = &p }
+}
+
+// backoff returns the delay before retry number attempt (0-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(mult, float64(attempt))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		j := min(p.Jitter, 1)
+		d -= d * j * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// shouldRetry reports whether err returned for call may be retried.
+// Apart from 429 responses, errors are only retried for idempotent methods and
+// requests with an idempotency key.
+func shouldRetry(ctx context.Context, call *Call, err error) bool {
+	if ctx.Err() != nil || !IsRetryable(err) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	return call.Method == http.MethodGet || call.Method == http.MethodDelete ||
+		call.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+// retryDelay returns how long to wait before retry number attempt, and whether to retry at all.
+func (c *Client) retryDelay(ctx context.Context, call *Call, attempt int, err error) (time.Duration, bool) {
+	if c.retry == nil || call.opts.NoRetries || attempt >= c.retry.MaxRetries || !shouldRetry(ctx, call, err) {
+		return 0, false
+	}
+	delay := c.retry.backoff(attempt)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		delay = apiErr.retryAfter
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value given either in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
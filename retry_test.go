@@ -0,0 +1,52 @@
+package cursor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	} {
+		require.Equal(t, tc.want, parseRetryAfter(tc.in), tc.in)
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	require.InDelta(t, time.Hour, parseRetryAfter(date), float64(2*time.Second))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	var got []time.Duration
+	for attempt := range 6 {
+		got = append(got, p.backoff(attempt))
+	}
+	require.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	}, got)
+
+	// Multipliers below 1 keep the delay constant.
+	p.Multiplier = 0.5
+	require.Equal(t, 100*time.Millisecond, p.backoff(3))
+
+	// Jitter only shortens the delay, by at most the given fraction.
+	p = RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, Jitter: 0.5}
+	for range 100 {
+		d := p.backoff(1)
+		require.GreaterOrEqual(t, d, time.Second)
+		require.LessOrEqual(t, d, 2*time.Second)
+	}
+}
@@ -0,0 +1,93 @@
+package cursor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AgentStatus is the lifecycle status of a background agent.
+// Values not known to this SDK version are preserved as-is; they are neither
+// terminal, active nor successful.
+type AgentStatus string
+
+// allowedTransitions lists the statuses each known status may move to.
+// Transitions that skip intermediate statuses are allowed because polling may miss them.
+// A finished agent may resume running after a follow-up.
+var allowedTransitions = map[AgentStatus][]AgentStatus{
+	AgentStatusCreating: {AgentStatusRunning, AgentStatusFinished, AgentStatusError, AgentStatusExpired},
+	AgentStatusRunning:  {AgentStatusFinished, AgentStatusError, AgentStatusExpired},
+	AgentStatusFinished: {AgentStatusRunning, AgentStatusExpired},
+	AgentStatusError:    {AgentStatusExpired},
+	AgentStatusExpired:  nil,
+}
+
+// IsKnown reports whether s is one of the statuses defined by this SDK.
+func (s AgentStatus) IsKnown() bool {
+	_, ok := allowedTransitions[s]
+	return ok
+}
+
+// IsTerminal reports whether the agent has stopped working (FINISHED, ERROR or EXPIRED).
+func (s AgentStatus) IsTerminal() bool {
+	return s == AgentStatusFinished || s == AgentStatusError || s == AgentStatusExpired
+}
+
+// IsActive reports whether the agent is still being created or running.
+func (s AgentStatus) IsActive() bool {
+	return s == AgentStatusCreating || s == AgentStatusRunning
+}
+
+// IsSuccess reports whether the agent finished successfully.
+func (s AgentStatus) IsSuccess() bool {
+	return s == AgentStatusFinished
+}
+
+// CanTransitionTo reports whether an agent may move from s to next.
+// Staying in the same status is always allowed; transitions involving unknown statuses are not.
+func (s AgentStatus) CanTransitionTo(next AgentStatus) bool {
+	if !s.IsKnown() || !next.IsKnown() {
+		return false
+	}
+	if s == next {
+		return true
+	}
+	for _, allowed := range allowedTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidTransitionError is returned by ValidateStatusTransition for disallowed transitions.
+type InvalidTransitionError struct {
+	From AgentStatus
+	To   AgentStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid agent status transition: %q -> %q", e.From, e.To)
+}
+
+// ValidateStatusTransition returns an *InvalidTransitionError if from cannot move to to.
+func ValidateStatusTransition(from, to AgentStatus) error {
+	if !from.CanTransitionTo(to) {
+		return &InvalidTransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a status string. A null value decodes to the empty
+// status; any other non-string value is an error.
+func (s *AgentStatus) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*s = ""
+		return nil
+	}
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("cursor: agent status must be a string, got %s", b)
+	}
+	*s = AgentStatus(v)
+	return nil
+}
@@ -0,0 +1,70 @@
+package cursor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentStatusTransitions(t *testing.T) {
+	const unknown AgentStatus = "PAUSED"
+	for _, tc := range []struct {
+		from, to AgentStatus
+		ok       bool
+	}{
+		{AgentStatusCreating, AgentStatusCreating, true},
+		{AgentStatusCreating, AgentStatusRunning, true},
+		{AgentStatusCreating, AgentStatusFinished, true},
+		{AgentStatusRunning, AgentStatusFinished, true},
+		{AgentStatusRunning, AgentStatusError, true},
+		{AgentStatusRunning, AgentStatusExpired, true},
+		{AgentStatusRunning, AgentStatusCreating, false},
+		{AgentStatusFinished, AgentStatusRunning, true},
+		{AgentStatusFinished, AgentStatusError, false},
+		{AgentStatusError, AgentStatusExpired, true},
+		{AgentStatusError, AgentStatusRunning, false},
+		{AgentStatusExpired, AgentStatusExpired, true},
+		{AgentStatusExpired, AgentStatusRunning, false},
+		{unknown, unknown, false},
+		{AgentStatusRunning, unknown, false},
+		{unknown, AgentStatusRunning, false},
+		{"", AgentStatusRunning, false},
+	} {
+		require.Equal(t, tc.ok, tc.from.CanTransitionTo(tc.to), "%s -> %s", tc.from, tc.to)
+		err := ValidateStatusTransition(tc.from, tc.to)
+		if tc.ok {
+			require.NoError(t, err)
+			continue
+		}
+		var invalid *InvalidTransitionError
+		require.ErrorAs(t, err, &invalid)
+		require.Equal(t, InvalidTransitionError{From: tc.from, To: tc.to}, *invalid)
+	}
+}
+
+func TestAgentStatusUnmarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    AgentStatus
+		wantErr bool
+	}{
+		{`"RUNNING"`, AgentStatusRunning, false},
+		{`"PAUSED"`, "PAUSED", false},
+		{`""`, "", false},
+		{`null`, "", false},
+		{`42`, "", true},
+		{`true`, "", true},
+		{`{"status":"RUNNING"}`, "", true},
+		{`["RUNNING"]`, "", true},
+	} {
+		var a Agent
+		err := json.Unmarshal([]byte(`{"id":"bc_1","status":`+tc.in+`}`), &a)
+		if tc.wantErr {
+			require.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.want, a.Status, tc.in)
+	}
+}
@@ -0,0 +1,89 @@
+package cursor
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// TailOptions configures TailConversation. The zero value uses sensible defaults.
+type TailOptions struct {
+	// Interval is the delay between polls (default: 2s).
+	Interval time.Duration
+	// SkipExisting omits the messages already present when tailing starts.
+	SkipExisting bool
+}
+
+// maxTailBackoff caps the delay between TailConversation polls after failures.
+const maxTailBackoff = 30 * time.Second
+
+// TailConversation returns an iterator over an agent's conversation messages,
+// following it as it grows. Every poll fetches the agent and its conversation and
+// yields only messages not seen before, keyed by Message.ID. Iteration ends after
+// the conversation has been fetched once the agent is in a terminal status, so
+// the final messages are included.
+// A poll failing with a retryable error (see IsRetryable) is retried with a
+// backoff of up to 30 seconds. Any other error, or the end of ctx, is
+// yielded once and iteration ends. opts may be nil; callOpts apply to every request.
+func (c *Client) TailConversation(ctx context.Context, id string, opts *TailOptions, callOpts ...CallOption) iter.Seq2[Message, error] {
+	var o TailOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = 2 * time.Second
+	}
+	return func(yield func(Message, error) bool) {
+		seen := make(map[string]bool)
+		first := true
+		backoff := o.Interval
+		for {
+			// Fetch the status first: if it is terminal, the conversation fetched
+			// afterwards is complete.
+			agent, err := c.GetAgent(ctx, id, callOpts...)
+			var conv *Conversation
+			if err == nil {
+				conv, err = c.GetConversation(ctx, id, callOpts...)
+			}
+			if err != nil {
+				if !IsRetryable(err) {
+					yield(Message{}, err)
+					return
+				}
+				backoff = min(2*backoff, max(maxTailBackoff, o.Interval))
+				if err := sleepContext(ctx, backoff); err != nil {
+					yield(Message{}, err)
+					return
+				}
+				continue
+			}
+			backoff = o.Interval
+			for i, m := range conv.Messages {
+				key := m.ID
+				if key == "" {
+					// Fall back to the position for messages without an ID.
+					key = "#" + strconv.Itoa(i)
+				}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if first && o.SkipExisting {
+					continue
+				}
+				if !yield(m, nil) {
+					return
+				}
+			}
+			first = false
+			if agent.Status.IsTerminal() {
+				return
+			}
+			if err := sleepContext(ctx, o.Interval); err != nil {
+				yield(Message{}, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package cursor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// TranscriptFormat selects the encoding used by Transcript.Export and ImportTranscript.
+type TranscriptFormat string
+
+// Transcript formats.
+const (
+	// TranscriptMarkdown is a readable Markdown document. Metadata is kept in
+	// HTML comments so the transcript can be imported again.
+	TranscriptMarkdown TranscriptFormat = "markdown"
+	// TranscriptHTML is a self-contained HTML page. The transcript is also
+	// embedded as JSON, so importing it is lossless.
+	TranscriptHTML TranscriptFormat = "html"
+	// TranscriptJSONL has a header line with the agent metadata followed by one
+	// line per message.
+	TranscriptJSONL TranscriptFormat = "jsonl"
+)
+
+// ErrInvalidTranscript is returned by ImportTranscript for malformed input.
+var ErrInvalidTranscript = errors.New("cursor: invalid transcript")
+
+// Transcript is a conversation together with the agent it belongs to, for archiving.
+type Transcript struct {
+	// Agent holds the agent metadata (source, target, summary); it may be nil.
+	Agent        *Agent       `json:"agent,omitempty"`
+	Conversation Conversation `json:"conversation"`
+}
+
+// Export writes c in format f, including agent metadata if agent is not nil.
+func (c *Conversation) Export(w io.Writer, f TranscriptFormat, agent *Agent) error {
+	t := Transcript{Agent: agent, Conversation: *c}
+	return t.Export(w, f)
+}
+
+// Export writes the transcript in format f.
+func (t *Transcript) Export(w io.Writer, f TranscriptFormat) error {
+	bw := bufio.NewWriter(w)
+	var err error
+	switch f {
+	case TranscriptMarkdown:
+		err = t.writeMarkdown(bw)
+	case TranscriptHTML:
+		err = t.writeHTML(bw)
+	case TranscriptJSONL:
+		err = t.writeJSONL(bw)
+	default:
+		return fmt.Errorf("cursor: unknown transcript format %q", f)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ImportTranscript reads a transcript written by Export in format f.
+func ImportTranscript(r io.Reader, f TranscriptFormat) (*Transcript, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch f {
+	case TranscriptMarkdown:
+		return readMarkdown(data)
+	case TranscriptHTML:
+		return readHTML(data)
+	case TranscriptJSONL:
+		return readJSONL(data)
+	}
+	return nil, fmt.Errorf("cursor: unknown transcript format %q", f)
+}
+
+// messageLabel returns a human-readable name for a message type.
+func messageLabel(typ string) string {
+	switch typ {
+	case "user_message":
+		return "User"
+	case "assistant_message":
+		return "Assistant"
+	}
+	return typ
+}
+
+// Markdown markers, each on a line of its own.
+const (
+	mdConversationMarker = "<!-- cursor:conversation "
+	mdAgentMarker        = "<!-- cursor:agent "
+	mdMessageMarker      = "<!-- cursor:message "
+	mdMarkerEnd          = " -->"
+)
+
+// mdMessageMeta is the JSON stored in a message marker.
+type mdMessageMeta struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type"`
+}
+
+// Markdown renders the message as a Markdown section: a heading with the
+// message type followed by the text.
+func (m Message) Markdown() string {
+	return "### " + messageLabel(m.Type) + "\n\n" + m.Text + "\n"
+}
+
+func (t *Transcript) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	conv, _ := json.Marshal(map[string]string{"id": t.Conversation.ID})
+	fmt.Fprintf(&b, "%s%s%s\n", mdConversationMarker, conv, mdMarkerEnd)
+	if a := t.Agent; a != nil {
+		meta, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		title := a.Name
+		if title == "" {
+			title = a.ID
+		}
+		fmt.Fprintf(&b, "%s%s%s\n# Agent %s\n\n", mdAgentMarker, meta, mdMarkerEnd, title)
+		for _, row := range agentMetadata(a) {
+			fmt.Fprintf(&b, "- **%s:** %s\n", row[0], row[1])
+		}
+		if a.Summary != nil && *a.Summary != "" {
+			fmt.Fprintf(&b, "\n**Summary:** %s\n", *a.Summary)
+		}
+	} else {
+		fmt.Fprintf(&b, "# Conversation %s\n", t.Conversation.ID)
+	}
+	b.WriteString("\n---\n")
+	for _, m := range t.Conversation.Messages {
+		meta, _ := json.Marshal(mdMessageMeta{ID: m.ID, Type: m.Type})
+		fmt.Fprintf(&b, "\n%s%s%s\n%s", mdMessageMarker, meta, mdMarkerEnd, m.Markdown())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// agentMetadata returns the non-empty agent fields shown in transcripts.
+func agentMetadata(a *Agent) [][2]string {
+	var rows [][2]string
+	add := func(name, value string) {
+		if value != "" {
+			rows = append(rows, [2]string{name, value})
+		}
+	}
+	add("ID", a.ID)
+	add("Status", string(a.Status))
+	add("Repository", a.Source.Repository)
+	add("Ref", a.Source.Ref)
+	add("Branch", a.Target.BranchName)
+	if a.Target.PRURL != nil {
+		add("Pull request", *a.Target.PRURL)
+	}
+	if !a.CreatedAt.IsZero() {
+		add("Created", a.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+	return rows
+}
+
+func parseMarker(line, prefix string, v any) (bool, error) {
+	if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, mdMarkerEnd) {
+		return false, nil
+	}
+	raw := strings.TrimSuffix(strings.TrimPrefix(line, prefix), mdMarkerEnd)
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return true, fmt.Errorf("%w: %v", ErrInvalidTranscript, err)
+	}
+	return true, nil
+}
+
+func readMarkdown(data []byte) (*Transcript, error) {
+	t := &Transcript{Conversation: Conversation{Messages: []Message{}}}
+	lines := strings.SplitAfter(string(data), "\n")
+	var cur *Message
+	var body strings.Builder
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		text := body.String()
+		// Drop the heading written by Message.Markdown and the separator before the next message.
+		if _, rest, ok := strings.Cut(text, "\n\n"); ok && strings.HasPrefix(text, "### ") {
+			text = rest
+		}
+		text = strings.TrimSuffix(text, "\n")
+		text = strings.TrimSuffix(text, "\n")
+		cur.Text = text
+		t.Conversation.Messages = append(t.Conversation.Messages, *cur)
+		cur = nil
+		body.Reset()
+	}
+	seenConversation := false
+	for _, raw := range lines {
+		line := strings.TrimRight(raw, "\r\n")
+		var meta mdMessageMeta
+		if ok, err := parseMarker(line, mdMessageMarker, &meta); err != nil {
+			return nil, err
+		} else if ok {
+			flush()
+			cur = &Message{ID: meta.ID, Type: meta.Type}
+			continue
+		}
+		if cur != nil {
+			body.WriteString(raw)
+			continue
+		}
+		var conv struct {
+			ID string `json:"id"`
+		}
+		if ok, err := parseMarker(line, mdConversationMarker, &conv); err != nil {
+			return nil, err
+		} else if ok {
+			t.Conversation.ID = conv.ID
+			seenConversation = true
+			continue
+		}
+		var agent Agent
+		if ok, err := parseMarker(line, mdAgentMarker, &agent); err != nil {
+			return nil, err
+		} else if ok {
+			t.Agent = &agent
+		}
+	}
+	// The last message is followed by a single newline instead of a separator.
+	body.WriteString("\n")
+	flush()
+	if !seenConversation {
+		return nil, fmt.Errorf("%w: missing conversation marker", ErrInvalidTranscript)
+	}
+	return t, nil
+}
+
+// HTML renders the message as an HTML element whose class is the message type.
+func (m Message) HTML() string {
+	return fmt.Sprintf("<div class=\"message %s\" data-id=\"%s\"><div class=\"label\">%s</div><div class=\"text\">%s</div></div>",
+		html.EscapeString(m.Type), html.EscapeString(m.ID), html.EscapeString(messageLabel(m.Type)), html.EscapeString(m.Text))
+}
+
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;color:#1f2328;background:#fff}
+h1{font-size:1.5em}
+table.meta{border-collapse:collapse;margin-bottom:1em}
+table.meta th{text-align:left;padding:2px 12px 2px 0;color:#59636e;font-weight:600}
+.summary{padding:.75em 1em;background:#f6f8fa;border-radius:6px;margin-bottom:1.5em}
+.message{border-radius:8px;padding:.75em 1em;margin:.75em 0;border:1px solid #d1d9e0}
+.message .label{font-size:.8em;font-weight:600;text-transform:uppercase;letter-spacing:.04em;color:#59636e;margin-bottom:.4em}
+.message .text{white-space:pre-wrap;overflow-wrap:anywhere}
+.user_message{background:#ddf4ff;border-color:#54aeff;margin-left:3em}
+.assistant_message{background:#f6f8fa}
+`
+
+// htmlDataID is the id of the script element embedding the transcript JSON.
+const htmlDataID = "cursor-transcript"
+
+func (t *Transcript) writeHTML(w io.Writer) error {
+	data, err := json.Marshal(t) // escapes <, > and &, so it is safe inside <script>
+	if err != nil {
+		return err
+	}
+	title := "Conversation " + t.Conversation.ID
+	if t.Agent != nil {
+		title = "Agent " + t.Agent.ID
+		if t.Agent.Name != "" {
+			title = "Agent " + t.Agent.Name
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(title))
+	if t.Agent != nil {
+		b.WriteString("<table class=\"meta\">\n")
+		for _, row := range agentMetadata(t.Agent) {
+			value := html.EscapeString(row[1])
+			if (row[0] == "Pull request" || row[0] == "Repository") && (strings.HasPrefix(row[1], "https://") || strings.HasPrefix(row[1], "http://")) {
+				value = fmt.Sprintf("<a href=\"%s\">%s</a>", value, value)
+			}
+			fmt.Fprintf(&b, "<tr><th>%s</th><td>%s</td></tr>\n", row[0], value)
+		}
+		b.WriteString("</table>\n")
+		if s := t.Agent.Summary; s != nil && *s != "" {
+			fmt.Fprintf(&b, "<div class=\"summary\">%s</div>\n", html.EscapeString(*s))
+		}
+	}
+	for _, m := range t.Conversation.Messages {
+		b.WriteString(m.HTML())
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "<script type=\"application/json\" id=\"%s\">%s</script>\n</body>\n</html>\n", htmlDataID, data)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func readHTML(data []byte) (*Transcript, error) {
+	start := []byte(`<script type="application/json" id="` + htmlDataID + `">`)
+	i := bytes.Index(data, start)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: no embedded transcript", ErrInvalidTranscript)
+	}
+	data = data[i+len(start):]
+	j := bytes.Index(data, []byte("</script>"))
+	if j < 0 {
+		return nil, fmt.Errorf("%w: unterminated embedded transcript", ErrInvalidTranscript)
+	}
+	var t Transcript
+	if err := json.Unmarshal(data[:j], &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTranscript, err)
+	}
+	return &t, nil
+}
+
+// jsonlRecord is a line of a JSONL transcript.
+type jsonlRecord struct {
+	Kind           string   `json:"kind"` // "conversation" or "message"
+	ConversationID string   `json:"conversationId,omitempty"`
+	Agent          *Agent   `json:"agent,omitempty"`
+	Message        *Message `json:"message,omitempty"`
+}
+
+func (t *Transcript) writeJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(jsonlRecord{Kind: "conversation", ConversationID: t.Conversation.ID, Agent: t.Agent}); err != nil {
+		return err
+	}
+	for i := range t.Conversation.Messages {
+		if err := enc.Encode(jsonlRecord{Kind: "message", Message: &t.Conversation.Messages[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSONL(data []byte) (*Transcript, error) {
+	t := &Transcript{Conversation: Conversation{Messages: []Message{}}}
+	header := false
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var rec jsonlRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidTranscript, n+1, err)
+		}
+		switch {
+		case rec.Kind == "conversation" && !header:
+			header = true
+			t.Conversation.ID = rec.ConversationID
+			t.Agent = rec.Agent
+		case rec.Kind == "message" && rec.Message != nil && header:
+			t.Conversation.Messages = append(t.Conversation.Messages, *rec.Message)
+		default:
+			return nil, fmt.Errorf("%w: line %d: unexpected %q record", ErrInvalidTranscript, n+1, rec.Kind)
+		}
+	}
+	if !header {
+		return nil, fmt.Errorf("%w: missing conversation header", ErrInvalidTranscript)
+	}
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package cursor_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+func TestTranscriptRoundTrip(t *testing.T) {
+	pr := "https://github.com/octocat/hello-world/pull/1"
+	summary := "Added a README <with> markup & \"quotes\" -->"
+	agent := &cursor.Agent{
+		ID:        "bc_1",
+		Name:      "Add README",
+		Status:    cursor.AgentStatusFinished,
+		Source:    cursor.Source{Repository: "https://github.com/octocat/hello-world", Ref: "main"},
+		Target:    cursor.Target{BranchName: "cursor/readme", URL: "https://cursor.com/agents?id=bc_1", PRURL: &pr},
+		Summary:   &summary,
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	conv := &cursor.Conversation{ID: "bc_1", Messages: []cursor.Message{
+		{ID: "m1", Type: "user_message", Text: "Add a README\n\n### with a heading\n"},
+		{ID: "m2", Type: "assistant_message", Text: "<script>alert(1)</script>"},
+		{ID: "m3", Type: "tool_call", Text: ""},
+		{ID: "m4", Type: "assistant_message", Text: "Done."},
+	}}
+
+	for _, f := range []cursor.TranscriptFormat{cursor.TranscriptMarkdown, cursor.TranscriptHTML, cursor.TranscriptJSONL} {
+		t.Run(string(f), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, conv.Export(&buf, f, agent))
+			got, err := cursor.ImportTranscript(bytes.NewReader(buf.Bytes()), f)
+			require.NoError(t, err)
+			require.Equal(t, *conv, got.Conversation)
+			require.Equal(t, agent.ID, got.Agent.ID)
+			require.Equal(t, agent.Source, got.Agent.Source)
+			require.Equal(t, agent.Target, got.Agent.Target)
+			require.Equal(t, summary, *got.Agent.Summary)
+			require.True(t, agent.CreatedAt.Equal(got.Agent.CreatedAt))
+
+			// Without agent metadata.
+			buf.Reset()
+			require.NoError(t, conv.Export(&buf, f, nil))
+			got, err = cursor.ImportTranscript(&buf, f)
+			require.NoError(t, err)
+			require.Nil(t, got.Agent)
+			require.Equal(t, *conv, got.Conversation)
+		})
+	}
+
+	var page bytes.Buffer
+	require.NoError(t, conv.Export(&page, cursor.TranscriptHTML, agent))
+	require.NotContains(t, page.String(), "<script>alert")
+	require.Contains(t, page.String(), `class="message user_message"`)
+	require.Contains(t, page.String(), `<a href="`+pr+`">`)
+
+	var md bytes.Buffer
+	require.NoError(t, conv.Export(&md, cursor.TranscriptMarkdown, agent))
+	require.Contains(t, md.String(), "- **Pull request:** "+pr)
+	require.Contains(t, md.String(), "### Assistant\n\nDone.\n")
+
+	_, err := cursor.ImportTranscript(strings.NewReader(`{"kind":"message","message":{}}`), cursor.TranscriptJSONL)
+	require.ErrorIs(t, err, cursor.ErrInvalidTranscript)
+	_, err = cursor.ImportTranscript(strings.NewReader("<html></html>"), cursor.TranscriptHTML)
+	require.ErrorIs(t, err, cursor.ErrInvalidTranscript)
+}
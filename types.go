@@ -6,22 +6,22 @@ import (
 
 // Possible values for Agent.Status.
 const (
-	AgentStatusRunning  = "RUNNING"
-	AgentStatusFinished = "FINISHED"
-	AgentStatusError    = "ERROR"
-	AgentStatusCreating = "CREATING"
-	AgentStatusExpired  = "EXPIRED"
+	AgentStatusRunning  AgentStatus = "RUNNING"
+	AgentStatusFinished AgentStatus = "FINISHED"
+	AgentStatusError    AgentStatus = "ERROR"
+	AgentStatusCreating AgentStatus = "CREATING"
+	AgentStatusExpired  AgentStatus = "EXPIRED"
 )
 
 // Agent represents a background agent task running in Cursor.
 type Agent struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name,omitempty"`
-	Status    string    `json:"status"`
-	Source    Source    `json:"source"`
-	Target    Target    `json:"target"`
-	Summary   *string   `json:"summary,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	Status    AgentStatus `json:"status"`
+	Source    Source      `json:"source"`
+	Target    Target      `json:"target"`
+	Summary   *string     `json:"summary,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
 }
 
 // Source identifies the input code repository and ref.
@@ -137,11 +137,11 @@ type MeResponse struct {
 
 // WebhookEvent is the payload for background agent webhook notifications.
 type WebhookEvent struct {
-	Event     string    `json:"event"`
-	Timestamp time.Time `json:"timestamp"`
-	ID        string    `json:"id"`
-	Status    string    `json:"status"`
-	Source    Source    `json:"source"`
-	Target    Target    `json:"target"`
-	Summary   *string   `json:"summary,omitempty"`
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	ID        string      `json:"id"`
+	Status    AgentStatus `json:"status"`
+	Source    Source      `json:"source"`
+	Target    Target      `json:"target"`
+	Summary   *string     `json:"summary,omitempty"`
 }
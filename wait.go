@@ -0,0 +1,94 @@
+package cursor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitOptions configures WaitForAgent. The zero value uses sensible defaults.
+type WaitOptions struct {
+	// InitialInterval is the delay between polls right after launch or a status change (default: 2s).
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between polls (default: 30s).
+	MaxInterval time.Duration
+	// Multiplier grows the delay while the status stays the same (default: 1.5).
+	Multiplier float64
+	// OnStatusChange, if set, is called with the agent every time its status changes,
+	// including the first observed status.
+	OnStatusChange func(agent *Agent)
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	var out WaitOptions
+	if o != nil {
+		out = *o
+	}
+	if out.InitialInterval <= 0 {
+		out.InitialInterval = 2 * time.Second
+	}
+	if out.MaxInterval <= 0 {
+		out.MaxInterval = 30 * time.Second
+	}
+	if out.MaxInterval < out.InitialInterval {
+		out.MaxInterval = out.InitialInterval
+	}
+	if out.Multiplier < 1 {
+		out.Multiplier = 1.5
+	}
+	return out
+}
+
+// AgentFailedError is returned by WaitForAgent when the agent ends with status ERROR or EXPIRED.
+type AgentFailedError struct {
+	Agent *Agent
+}
+
+func (e *AgentFailedError) Error() string {
+	return fmt.Sprintf("agent %s ended with status %s", e.Agent.ID, e.Agent.Status)
+}
+
+// WaitForAgent polls GetAgent until the agent reaches a terminal status.
+// Polling starts at opts.InitialInterval and backs off while the status is unchanged.
+// A poll failing with a retryable error (see IsRetryable) is retried after the
+// next backoff interval; other errors and the end of ctx stop the wait.
+// It returns the final agent on FINISHED, and the final agent together with an
+// *AgentFailedError on ERROR or EXPIRED. opts may be nil; callOpts apply to every poll.
+func (c *Client) WaitForAgent(ctx context.Context, id string, opts *WaitOptions, callOpts ...CallOption) (*Agent, error) {
+	o := opts.withDefaults()
+	interval := o.InitialInterval
+	var last AgentStatus
+	for {
+		agent, err := c.GetAgent(ctx, id, callOpts...)
+		if err != nil {
+			if !IsRetryable(err) {
+				return nil, err
+			}
+			interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+			if err := sleepContext(ctx, interval); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if agent.Status != last {
+			last = agent.Status
+			interval = o.InitialInterval
+			if o.OnStatusChange != nil {
+				o.OnStatusChange(agent)
+			}
+		} else {
+			interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+		}
+
+		if agent.Status.IsTerminal() {
+			if agent.Status.IsSuccess() {
+				return agent, nil
+			}
+			return agent, &AgentFailedError{Agent: agent}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
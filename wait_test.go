@@ -0,0 +1,92 @@
+package cursor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitOptionsDefaults(t *testing.T) {
+	var nilOpts *WaitOptions
+	require.Equal(t, WaitOptions{InitialInterval: 2 * time.Second, MaxInterval: 30 * time.Second, Multiplier: 1.5}, nilOpts.withDefaults())
+
+	o := (&WaitOptions{InitialInterval: time.Minute, MaxInterval: time.Second, Multiplier: 0.5}).withDefaults()
+	require.Equal(t, time.Minute, o.MaxInterval, "MaxInterval is raised to InitialInterval")
+	require.Equal(t, 1.5, o.Multiplier)
+}
+
+// statusServer serves GET /v0/agents/{id} returning statuses in order, repeating the last one.
+func statusServer(t *testing.T, statuses ...AgentStatus) (*Client, *atomic.Int32) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(polls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		_ = json.NewEncoder(w).Encode(Agent{ID: "bc_1", Status: status})
+	}))
+	t.Cleanup(srv.Close)
+	return New("key", WithBaseURL(srv.URL)), &polls
+}
+
+func TestWaitForAgent(t *testing.T) {
+	c, polls := statusServer(t, AgentStatusCreating, AgentStatusRunning, AgentStatusRunning, AgentStatusRunning, AgentStatusFinished)
+	var changes []AgentStatus
+	agent, err := c.WaitForAgent(context.Background(), "bc_1", &WaitOptions{
+		InitialInterval: time.Millisecond,
+		OnStatusChange:  func(a *Agent) { changes = append(changes, a.Status) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, AgentStatusFinished, agent.Status)
+	require.Equal(t, []AgentStatus{AgentStatusCreating, AgentStatusRunning, AgentStatusFinished}, changes)
+	require.EqualValues(t, 5, polls.Load())
+
+	c, _ = statusServer(t, AgentStatusRunning, AgentStatusExpired)
+	agent, err = c.WaitForAgent(context.Background(), "bc_1", &WaitOptions{InitialInterval: time.Millisecond})
+	var failed *AgentFailedError
+	require.ErrorAs(t, err, &failed)
+	require.Equal(t, AgentStatusExpired, agent.Status)
+	require.Same(t, agent, failed.Agent)
+
+	// The context ends the wait between polls.
+	c, _ = statusServer(t, AgentStatusRunning)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	agent, err = c.WaitForAgent(ctx, "bc_1", &WaitOptions{InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, agent)
+}
+
+func TestWaitForAgentRetriesFailedPolls(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch polls.Add(1) {
+		case 1:
+			_ = json.NewEncoder(w).Encode(Agent{ID: "bc_1", Status: AgentStatusRunning})
+		case 2, 3:
+			http.Error(w, `{"error":"bad gateway"}`, http.StatusBadGateway)
+		default:
+			_ = json.NewEncoder(w).Encode(Agent{ID: "bc_1", Status: AgentStatusFinished})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	// No retry policy: WaitForAgent itself keeps polling through the 502s.
+	c := New("key", WithBaseURL(srv.URL))
+	agent, err := c.WaitForAgent(context.Background(), "bc_1", &WaitOptions{InitialInterval: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, AgentStatusFinished, agent.Status)
+	require.EqualValues(t, 4, polls.Load())
+
+	// Errors that are not retryable end the wait.
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	}))
+	t.Cleanup(notFound.Close)
+	_, err = New("key", WithBaseURL(notFound.URL)).WaitForAgent(context.Background(), "bc_1", &WaitOptions{InitialInterval: time.Millisecond})
+	require.ErrorIs(t, err, ErrNotFound)
+}
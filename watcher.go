@@ -0,0 +1,359 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventSource tells where an AgentEvent was observed.
+type EventSource string
+
+// Event sources.
+const (
+	EventSourceWebhook EventSource = "webhook"
+	EventSourcePoll    EventSource = "poll"
+)
+
+// AgentEvent reports the status of an agent observed by a Watcher.
+type AgentEvent struct {
+	AgentID string
+	Status  AgentStatus
+	// Agent is the agent as observed. Events from webhooks only carry the
+	// fields present in the WebhookEvent.
+	Agent  *Agent
+	Source EventSource
+	// Time is the webhook timestamp or the time the poll was started.
+	Time time.Time
+}
+
+// WatcherOptions configures a Watcher. The zero value uses sensible defaults.
+type WatcherOptions struct {
+	// PollInterval is the delay between polls of agents for which no webhook
+	// has been received (default: 10s).
+	PollInterval time.Duration
+	// WebhookPollInterval is the delay between polls once a webhook has been
+	// received for an agent, to catch lost deliveries (default: 2m).
+	// A negative value stops polling such agents.
+	WebhookPollInterval time.Duration
+	// PageSize is the number of agents fetched per poll by WatchAll (default: 100).
+	PageSize int
+	// OnError, if set, is called with polling errors. Polling continues after
+	// errors, except ErrNotFound which ends the watch of that agent.
+	OnError func(agentID string, err error)
+}
+
+func (o *WatcherOptions) withDefaults() WatcherOptions {
+	var out WatcherOptions
+	if o != nil {
+		out = *o
+	}
+	if out.PollInterval <= 0 {
+		out.PollInterval = 10 * time.Second
+	}
+	if out.WebhookPollInterval == 0 {
+		out.WebhookPollInterval = 2 * time.Minute
+	}
+	if out.PageSize <= 0 {
+		out.PageSize = 100
+	}
+	return out
+}
+
+// Watcher merges webhook deliveries and GetAgent polling into a single stream
+// of AgentEvents. Feed it webhooks with HandleWebhook, e.g. router.OnAny(w.HandleWebhook);
+// agents without webhooks are still observed by polling.
+//
+// Every subscriber receives each distinct status of an agent once. Events are
+// ordered by the agent lifecycle rather than by their times, which come from the
+// server's and the local clock: repeated statuses and statuses that cannot
+// follow the latest one (e.g. RUNNING after ERROR) are dropped. An agent
+// resuming after a follow-up is reported once a poll started after its
+// terminal status, or a newer webhook, shows it running.
+type Watcher struct {
+	api  AgentsAPI
+	opts WatcherOptions
+
+	mu       sync.Mutex
+	agents   map[string]*agentWatchState
+	terminal []string // agents in a terminal status, oldest first
+	subs     map[*watchSub]struct{}
+}
+
+// maxTerminalAgents is the number of agents in a terminal status that a Watcher
+// remembers to drop their late events; the oldest ones are forgotten.
+const maxTerminalAgents = 1000
+
+type agentWatchState struct {
+	status   AgentStatus // status of the latest accepted event
+	source   EventSource // source of the latest accepted event
+	time     time.Time   // AgentEvent.Time of the latest accepted event
+	seen     time.Time   // local time the latest event was accepted
+	webhooks bool        // a webhook was accepted for the agent
+}
+
+// accepts reports whether ev may follow the latest accepted event. Repeated
+// statuses are accepted here and dropped per subscriber.
+func (st *agentWatchState) accepts(ev AgentEvent) bool {
+	switch {
+	case st.status == "" || st.status == ev.Status:
+		return true
+	case !st.status.IsKnown() || !ev.Status.IsKnown():
+		return true
+	case !st.status.CanTransitionTo(ev.Status):
+		return false
+	case st.status.IsTerminal() && ev.Status.IsActive():
+		// Either a follow-up resumed the agent or ev is older than the terminal
+		// status. Only compare times taken from the same clock.
+		if ev.Source == EventSourcePoll {
+			return ev.Time.After(st.seen)
+		}
+		return st.source == EventSourceWebhook && ev.Time.After(st.time)
+	}
+	return true
+}
+
+type watchSub struct {
+	agentID string // empty for all agents
+
+	// Guarded by Watcher.mu.
+	last    map[string]AgentStatus
+	queue   []AgentEvent
+	closing bool
+
+	wake chan struct{}
+}
+
+// NewWatcher creates a Watcher polling api. opts may be nil.
+func NewWatcher(api AgentsAPI, opts *WatcherOptions) *Watcher {
+	return &Watcher{
+		api:    api,
+		opts:   opts.withDefaults(),
+		agents: make(map[string]*agentWatchState),
+		subs:   make(map[*watchSub]struct{}),
+	}
+}
+
+// Watch returns a channel of status events for the agent with the given id.
+// The first observed status is sent right away. The channel is closed after a
+// terminal status is sent, when the agent is not found, or when ctx ends.
+// To keep following an agent after a follow-up, call Watch again.
+func (w *Watcher) Watch(ctx context.Context, id string) <-chan AgentEvent {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := w.subscribe(id)
+	out := make(chan AgentEvent)
+	go func() {
+		defer cancel()
+		w.pump(ctx, sub, out)
+	}()
+	go w.pollAgent(ctx, sub)
+	return out
+}
+
+// WatchAll returns a channel of status events for all agents: those listed by
+// ListAgents (the most recent PageSize agents) and those delivered by webhooks.
+// The channel is closed when ctx ends.
+func (w *Watcher) WatchAll(ctx context.Context) <-chan AgentEvent {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := w.subscribe("")
+	out := make(chan AgentEvent)
+	go func() {
+		defer cancel()
+		w.pump(ctx, sub, out)
+	}()
+	go w.pollAll(ctx)
+	return out
+}
+
+// HandleWebhook feeds a webhook event to the watcher. Its signature matches
+// WebhookHandlerFunc, so it can be registered on a WebhookRouter.
+func (w *Watcher) HandleWebhook(ctx context.Context, ev *WebhookEvent) error {
+	if ev.Event != "" && ev.Event != WebhookEventStatusChange {
+		return nil
+	}
+	t := ev.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	w.publish(AgentEvent{
+		AgentID: ev.ID,
+		Status:  ev.Status,
+		Agent:   &Agent{ID: ev.ID, Status: ev.Status, Source: ev.Source, Target: ev.Target, Summary: ev.Summary},
+		Source:  EventSourceWebhook,
+		Time:    t,
+	})
+	return nil
+}
+
+func (w *Watcher) subscribe(agentID string) *watchSub {
+	sub := &watchSub{agentID: agentID, last: make(map[string]AgentStatus), wake: make(chan struct{}, 1)}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs[sub] = struct{}{}
+	return sub
+}
+
+// publish queues ev for every matching subscriber that has not seen its status.
+func (w *Watcher) publish(ev AgentEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.agents[ev.AgentID]
+	if st == nil {
+		st = &agentWatchState{}
+		w.agents[ev.AgentID] = st
+	}
+	if !st.accepts(ev) {
+		return
+	}
+	wasTerminal := st.status.IsTerminal()
+	st.status, st.source, st.time, st.seen = ev.Status, ev.Source, ev.Time, time.Now()
+	if ev.Source == EventSourceWebhook {
+		st.webhooks = true
+	}
+	switch {
+	case ev.Status.IsTerminal() && !wasTerminal:
+		w.retire(ev.AgentID)
+	case !ev.Status.IsTerminal() && wasTerminal:
+		w.terminal = slices.DeleteFunc(w.terminal, func(id string) bool { return id == ev.AgentID })
+	}
+
+	for sub := range w.subs {
+		if sub.closing || (sub.agentID != "" && sub.agentID != ev.AgentID) {
+			continue
+		}
+		if last, ok := sub.last[ev.AgentID]; ok && last == ev.Status {
+			continue
+		}
+		sub.last[ev.AgentID] = ev.Status
+		sub.queue = append(sub.queue, ev)
+		if sub.agentID != "" && ev.Status.IsTerminal() {
+			sub.closing = true
+		}
+		sub.signal()
+	}
+}
+
+// retire records that the agent id reached a terminal status and forgets the
+// oldest terminal agents beyond maxTerminalAgents, also in every subscription.
+// The caller holds w.mu.
+func (w *Watcher) retire(id string) {
+	w.terminal = append(w.terminal, id)
+	for len(w.terminal) > maxTerminalAgents {
+		oldest := w.terminal[0]
+		delete(w.agents, oldest)
+		for sub := range w.subs {
+			delete(sub.last, oldest)
+		}
+		w.terminal = w.terminal[1:]
+	}
+}
+
+func (s *watchSub) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// end closes the subscription after its queued events are sent.
+func (w *Watcher) end(sub *watchSub) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sub.closing = true
+	sub.signal()
+}
+
+// pump sends queued events to out until the subscription ends.
+func (w *Watcher) pump(ctx context.Context, sub *watchSub, out chan<- AgentEvent) {
+	defer close(out)
+	defer func() {
+		w.mu.Lock()
+		delete(w.subs, sub)
+		w.mu.Unlock()
+	}()
+	for {
+		w.mu.Lock()
+		batch, closing := sub.queue, sub.closing
+		sub.queue = nil
+		w.mu.Unlock()
+
+		for _, ev := range batch {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if closing {
+			return
+		}
+		select {
+		case <-sub.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Watcher) hasWebhooks(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.agents[id]
+	return st != nil && st.webhooks
+}
+
+func (w *Watcher) pollAgent(ctx context.Context, sub *watchSub) {
+	id := sub.agentID
+	for {
+		start := time.Now()
+		agent, err := w.api.GetAgent(ctx, id)
+		switch {
+		case err == nil:
+			w.publish(AgentEvent{AgentID: id, Status: agent.Status, Agent: agent, Source: EventSourcePoll, Time: start})
+		case ctx.Err() != nil:
+			return
+		default:
+			if w.opts.OnError != nil {
+				w.opts.OnError(id, err)
+			}
+			if errors.Is(err, ErrNotFound) {
+				w.end(sub)
+				return
+			}
+		}
+
+		interval := w.opts.PollInterval
+		if w.hasWebhooks(id) {
+			if w.opts.WebhookPollInterval < 0 {
+				return
+			}
+			interval = w.opts.WebhookPollInterval
+		}
+		if sleepContext(ctx, interval) != nil {
+			return
+		}
+	}
+}
+
+func (w *Watcher) pollAll(ctx context.Context) {
+	for {
+		start := time.Now()
+		resp, err := w.api.ListAgents(ctx, w.opts.PageSize, nil)
+		switch {
+		case err == nil:
+			for i := range resp.Agents {
+				a := &resp.Agents[i]
+				w.publish(AgentEvent{AgentID: a.ID, Status: a.Status, Agent: a, Source: EventSourcePoll, Time: start})
+			}
+		case ctx.Err() != nil:
+			return
+		case w.opts.OnError != nil:
+			w.opts.OnError("", err)
+		}
+		if sleepContext(ctx, w.opts.PollInterval) != nil {
+			return
+		}
+	}
+}
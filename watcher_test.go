@@ -0,0 +1,72 @@
+package cursor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentWatchStateAccepts(t *testing.T) {
+	now := time.Now()
+	finished := agentWatchState{status: AgentStatusFinished, source: EventSourceWebhook, time: now, seen: now}
+	for _, tt := range []struct {
+		name   string
+		st     agentWatchState
+		ev     AgentEvent
+		accept bool
+	}{
+		{"first event", agentWatchState{}, AgentEvent{Status: AgentStatusRunning}, true},
+		{"forward", agentWatchState{status: AgentStatusCreating}, AgentEvent{Status: AgentStatusFinished, Time: now.Add(-time.Hour)}, true},
+		{"backward", agentWatchState{status: AgentStatusRunning}, AgentEvent{Status: AgentStatusCreating, Time: now.Add(time.Hour)}, false},
+		{"after error", agentWatchState{status: AgentStatusError}, AgentEvent{Status: AgentStatusRunning}, false},
+		{"unknown status", agentWatchState{status: AgentStatusRunning}, AgentEvent{Status: "PAUSED"}, true},
+		{"older webhook after finished", finished, AgentEvent{Status: AgentStatusRunning, Source: EventSourceWebhook, Time: now.Add(-time.Second)}, false},
+		{"newer webhook after finished", finished, AgentEvent{Status: AgentStatusRunning, Source: EventSourceWebhook, Time: now.Add(time.Second)}, true},
+		{"poll started before finished", finished, AgentEvent{Status: AgentStatusRunning, Source: EventSourcePoll, Time: now.Add(-time.Second)}, false},
+		{"poll started after finished", finished, AgentEvent{Status: AgentStatusRunning, Source: EventSourcePoll, Time: now.Add(time.Second)}, true},
+		{"webhook after polled finish", agentWatchState{status: AgentStatusFinished, source: EventSourcePoll, time: now, seen: now}, AgentEvent{Status: AgentStatusRunning, Source: EventSourceWebhook, Time: now.Add(time.Hour)}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.accept, tt.st.accepts(tt.ev))
+		})
+	}
+}
+
+func TestWatcherForgetsTerminalAgents(t *testing.T) {
+	w := NewWatcher(nil, nil)
+	for i := range maxTerminalAgents + 10 {
+		id := fmt.Sprintf("bc_%d", i)
+		w.publish(AgentEvent{AgentID: id, Status: AgentStatusRunning, Source: EventSourcePoll, Time: time.Now()})
+		w.publish(AgentEvent{AgentID: id, Status: AgentStatusFinished, Source: EventSourcePoll, Time: time.Now()})
+	}
+	w.publish(AgentEvent{AgentID: "bc_active", Status: AgentStatusRunning, Source: EventSourcePoll, Time: time.Now()})
+	require.Len(t, w.agents, maxTerminalAgents+1)
+	require.NotContains(t, w.agents, "bc_0")
+	require.Contains(t, w.agents, "bc_active")
+
+	// A resumed agent leaves the terminal list.
+	id := fmt.Sprintf("bc_%d", maxTerminalAgents+9)
+	w.publish(AgentEvent{AgentID: id, Status: AgentStatusRunning, Source: EventSourcePoll, Time: time.Now().Add(time.Second)})
+	require.Len(t, w.terminal, maxTerminalAgents-1)
+	require.Equal(t, AgentStatusRunning, w.agents[id].status)
+}
+
+func TestWatcherSubscriptionsForgetTerminalAgents(t *testing.T) {
+	w := NewWatcher(nil, nil)
+	sub := w.subscribe("")
+	for i := range maxTerminalAgents + 10 {
+		id := fmt.Sprintf("bc_%d", i)
+		w.publish(AgentEvent{AgentID: id, Status: AgentStatusRunning, Source: EventSourcePoll, Time: time.Now()})
+		w.publish(AgentEvent{AgentID: id, Status: AgentStatusFinished, Source: EventSourcePoll, Time: time.Now()})
+	}
+	w.publish(AgentEvent{AgentID: "bc_active", Status: AgentStatusRunning, Source: EventSourcePoll, Time: time.Now()})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	require.Len(t, sub.last, maxTerminalAgents+1)
+	require.NotContains(t, sub.last, "bc_0")
+	require.Contains(t, sub.last, "bc_active")
+	require.Equal(t, AgentStatusFinished, sub.last[fmt.Sprintf("bc_%d", maxTerminalAgents+9)])
+}
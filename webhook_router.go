@@ -0,0 +1,204 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sync"
+)
+
+// WebhookEventStatusChange is the WebhookEvent.Event value sent when an agent changes status.
+const WebhookEventStatusChange = "statusChange"
+
+// maxWebhookBody limits the size of webhook payloads accepted by WebhookRouter.
+const maxWebhookBody = 5 << 20
+
+// WebhookHandlerFunc handles a verified and decoded webhook event.
+type WebhookHandlerFunc func(ctx context.Context, ev *WebhookEvent) error
+
+// WebhookError lets a handler choose the HTTP status code returned for its error.
+type WebhookError struct {
+	StatusCode int
+	Err        error
+}
+
+// NewWebhookError wraps err so that WebhookRouter responds with statusCode.
+func NewWebhookError(statusCode int, err error) *WebhookError {
+	return &WebhookError{StatusCode: statusCode, Err: err}
+}
+
+func (e *WebhookError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.StatusCode)
+	}
+	return e.Err.Error()
+}
+
+func (e *WebhookError) Unwrap() error { return e.Err }
+
+// WebhookPanicError is reported when a webhook handler panics.
+type WebhookPanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *WebhookPanicError) Error() string {
+	return fmt.Sprintf("webhook handler panic: %v", e.Value)
+}
+
+// WebhookRouter is an http.Handler that verifies webhook signatures, decodes
+// WebhookEvent payloads and dispatches them to registered handlers.
+// Verification, including replay protection, is done by a WebhookVerifier.
+//
+// Handlers can access the verified delivery, including the matched secret ID,
+// via WebhookDeliveryFromContext.
+//
+// For every event, OnAny handlers run first, then OnStatusChange handlers (for
+// statusChange events), then handlers registered for the event's status.
+// Dispatch stops at the first error. Responses are:
+//   - 204 when all handlers succeed (or none match);
+//   - 405 for non-POST requests, 400 for unreadable, malformed or stale payloads,
+//     401 for invalid signatures, 503 if the secrets cannot be resolved;
+//   - 204 without dispatching for duplicate deliveries;
+//   - the status of a *WebhookError returned by a handler;
+//   - 503 when the request context ends during handling;
+//   - 500 for any other handler error or a recovered panic.
+type WebhookRouter struct {
+	verifier *WebhookVerifier
+
+	mu           sync.RWMutex
+	any          []WebhookHandlerFunc
+	statusChange []WebhookHandlerFunc
+	byStatus     map[AgentStatus][]WebhookHandlerFunc
+	onErr        func(r *http.Request, ev *WebhookEvent, err error)
+}
+
+// NewWebhookRouter creates a router verifying deliveries with NewWebhookVerifier(secret, opts...).
+// Like NewWebhookVerifier, it returns ErrNoWebhookSecret without a secret; pass
+// WithoutSignatureVerification for webhooks launched without one.
+func NewWebhookRouter(secret string, opts ...VerifyOption) (*WebhookRouter, error) {
+	v, err := NewWebhookVerifier(secret, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookRouter{
+		verifier: v,
+		byStatus: make(map[AgentStatus][]WebhookHandlerFunc),
+	}, nil
+}
+
+// OnAny registers h for every event.
+func (wr *WebhookRouter) OnAny(h WebhookHandlerFunc) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.any = append(wr.any, h)
+}
+
+// OnStatusChange registers h for every statusChange event.
+func (wr *WebhookRouter) OnStatusChange(h WebhookHandlerFunc) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.statusChange = append(wr.statusChange, h)
+}
+
+// OnStatus registers h for events reporting the given status.
+func (wr *WebhookRouter) OnStatus(status AgentStatus, h WebhookHandlerFunc) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.byStatus[status] = append(wr.byStatus[status], h)
+}
+
+// OnFinished registers h for events reporting FINISHED.
+func (wr *WebhookRouter) OnFinished(h WebhookHandlerFunc) { wr.OnStatus(AgentStatusFinished, h) }
+
+// OnError registers h for events reporting ERROR.
+func (wr *WebhookRouter) OnError(h WebhookHandlerFunc) { wr.OnStatus(AgentStatusError, h) }
+
+// OnExpired registers h for events reporting EXPIRED.
+func (wr *WebhookRouter) OnExpired(h WebhookHandlerFunc) { wr.OnStatus(AgentStatusExpired, h) }
+
+// OnHandlerError sets a callback invoked with every handler error or recovered
+// panic, e.g. for logging.
+func (wr *WebhookRouter) OnHandlerError(fn func(r *http.Request, ev *WebhookEvent, err error)) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	wr.onErr = fn
+}
+
+// ServeHTTP implements http.Handler.
+func (wr *WebhookRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	d, err := wr.verifier.Verify(r, body)
+	if err != nil {
+		code := webhookVerifyStatus(err)
+		if code == http.StatusNoContent {
+			w.WriteHeader(code)
+			return
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), webhookDeliveryKey{}, d)
+	if err := wr.Dispatch(ctx, &d.Event); err != nil {
+		// Allow the sender's retry to be processed.
+		wr.verifier.Forget(d)
+		wr.mu.RLock()
+		onErr := wr.onErr
+		wr.mu.RUnlock()
+		if onErr != nil {
+			onErr(r, &d.Event, err)
+		}
+		code := http.StatusInternalServerError
+		var werr *WebhookError
+		switch {
+		case errors.As(err, &werr):
+			code = werr.StatusCode
+		case r.Context().Err() != nil:
+			code = http.StatusServiceUnavailable
+		}
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Dispatch runs the handlers matching ev without any HTTP handling.
+// A panicking handler is recovered and reported as a *WebhookPanicError.
+func (wr *WebhookRouter) Dispatch(ctx context.Context, ev *WebhookEvent) error {
+	wr.mu.RLock()
+	handlers := append([]WebhookHandlerFunc(nil), wr.any...)
+	if ev.Event == WebhookEventStatusChange {
+		handlers = append(handlers, wr.statusChange...)
+	}
+	handlers = append(handlers, wr.byStatus[ev.Status]...)
+	wr.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := callWebhookHandler(ctx, h, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func callWebhookHandler(ctx context.Context, h WebhookHandlerFunc, ev *WebhookEvent) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &WebhookPanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return h(ctx, ev)
+}
@@ -0,0 +1,312 @@
+package cursor
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Errors returned by WebhookVerifier.
+var (
+	ErrNoWebhookSecret       = errors.New("cursor: webhook secret is empty")
+	ErrInvalidSignature      = errors.New("cursor: invalid webhook signature")
+	ErrInvalidWebhookPayload = errors.New("cursor: invalid webhook payload")
+	ErrStaleWebhook          = errors.New("cursor: webhook timestamp outside tolerance")
+	ErrDuplicateWebhook      = errors.New("cursor: duplicate webhook delivery")
+	ErrSecretUnavailable     = errors.New("cursor: webhook secret unavailable")
+)
+
+// PrimarySecretID is the WebhookSecret.ID of the secret passed to NewWebhookVerifier.
+const PrimarySecretID = "primary"
+
+// WebhookSecret is a named webhook signing secret.
+// The ID is reported back in WebhookDelivery.KeyID when the secret matches.
+type WebhookSecret struct {
+	ID     string
+	Secret string
+}
+
+// SecretProvider resolves the secrets accepted for a request, e.g. per tenant or
+// from a secret manager during rotation.
+type SecretProvider func(r *http.Request) ([]WebhookSecret, error)
+
+// VerifySignatureAny validates the signature header against each secret in turn
+// and returns the first secret that matches.
+func VerifySignatureAny(secrets []WebhookSecret, body []byte, signatureHeader string) (WebhookSecret, bool) {
+	for _, s := range secrets {
+		if VerifySignature(s.Secret, body, signatureHeader) {
+			return s, true
+		}
+	}
+	return WebhookSecret{}, false
+}
+
+// DefaultWebhookTolerance is the default maximum skew between a webhook's timestamp and the local clock.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// DeliveryStore remembers webhook deliveries that have already been accepted.
+// Implementations must be safe for concurrent use.
+type DeliveryStore interface {
+	// Add records key and reports whether it was not recorded before.
+	Add(key string) bool
+	// Remove forgets key so that a redelivery is accepted again.
+	Remove(key string)
+}
+
+// LRUDeliveryStore is an in-memory DeliveryStore that keeps the most recent keys.
+type LRUDeliveryStore struct {
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List // front is most recent
+	keys  map[string]*list.Element
+}
+
+// NewLRUDeliveryStore creates a store remembering up to capacity keys.
+func NewLRUDeliveryStore(capacity int) *LRUDeliveryStore {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &LRUDeliveryStore{
+		capacity: capacity,
+		order:    list.New(),
+		keys:     make(map[string]*list.Element, capacity),
+	}
+}
+
+// Add implements DeliveryStore.
+func (s *LRUDeliveryStore) Add(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.keys[key]; ok {
+		s.order.MoveToFront(el)
+		return false
+	}
+	s.keys[key] = s.order.PushFront(key)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.keys, oldest.Value.(string))
+	}
+	return true
+}
+
+// Remove implements DeliveryStore.
+func (s *LRUDeliveryStore) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.keys[key]; ok {
+		s.order.Remove(el)
+		delete(s.keys, key)
+	}
+}
+
+// VerifyOption configures a WebhookVerifier.
+type VerifyOption func(*WebhookVerifier)
+
+// WithTimestampTolerance sets the maximum allowed skew between WebhookEvent.Timestamp
+// and the local clock. Zero disables the timestamp check.
+func WithTimestampTolerance(d time.Duration) VerifyOption {
+	return func(v *WebhookVerifier) { v.tolerance = d }
+}
+
+// WithDeliveryStore sets the store used to drop duplicate deliveries. Nil disables deduplication.
+func WithDeliveryStore(s DeliveryStore) VerifyOption {
+	return func(v *WebhookVerifier) { v.store = s }
+}
+
+// WithSecrets adds secrets accepted in addition to the primary secret,
+// e.g. the previous secret while agents launched with it are still running.
+func WithSecrets(secrets ...WebhookSecret) VerifyOption {
+	return func(v *WebhookVerifier) { v.secrets = append(v.secrets, secrets...) }
+}
+
+// WithSecretProvider resolves accepted secrets per request, replacing the static secrets.
+// If the provider returns no secrets, every delivery is rejected as unsigned.
+func WithSecretProvider(p SecretProvider) VerifyOption {
+	return func(v *WebhookVerifier) { v.provider = p }
+}
+
+// WithoutSignatureVerification accepts deliveries without checking their
+// signature, for webhooks launched without a secret. Timestamp and duplicate
+// checks still apply.
+func WithoutSignatureVerification() VerifyOption {
+	return func(v *WebhookVerifier) { v.unsigned = true }
+}
+
+// WithVerifierClock overrides the clock used for timestamp checks.
+func WithVerifierClock(now func() time.Time) VerifyOption {
+	return func(v *WebhookVerifier) { v.now = now }
+}
+
+// WebhookDelivery is a verified webhook delivery.
+type WebhookDelivery struct {
+	Event WebhookEvent
+	Body  []byte
+	// Key identifies the delivery in the DeliveryStore (agent ID + event timestamp).
+	Key string
+	// KeyID is the ID of the secret that matched the signature, or empty if
+	// signature verification is disabled with WithoutSignatureVerification.
+	KeyID string
+}
+
+// WebhookVerifier checks webhook signatures and protects against replayed deliveries.
+// A signature is accepted if it matches any active secret, which allows rotating
+// secrets without downtime. Events whose timestamp is outside the tolerance window
+// are rejected, and deliveries already seen (by agent ID + timestamp) are reported
+// as duplicates.
+type WebhookVerifier struct {
+	secrets   []WebhookSecret
+	provider  SecretProvider
+	unsigned  bool
+	tolerance time.Duration
+	store     DeliveryStore
+	now       func() time.Time
+}
+
+// NewWebhookVerifier creates a verifier for secret, reported as PrimarySecretID.
+// By default it allows DefaultWebhookTolerance of clock skew and remembers the last
+// 10000 deliveries in memory. It returns ErrNoWebhookSecret if no secret is given,
+// neither as secret nor through WithSecrets or WithSecretProvider, unless
+// WithoutSignatureVerification is passed.
+func NewWebhookVerifier(secret string, opts ...VerifyOption) (*WebhookVerifier, error) {
+	v := &WebhookVerifier{
+		tolerance: DefaultWebhookTolerance,
+		store:     NewLRUDeliveryStore(0),
+		now:       time.Now,
+	}
+	if secret != "" {
+		v.secrets = []WebhookSecret{{ID: PrimarySecretID, Secret: secret}}
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if len(v.secrets) == 0 && v.provider == nil && !v.unsigned {
+		return nil, ErrNoWebhookSecret
+	}
+	return v, nil
+}
+
+// Verify checks the signature of body against the request's X-Webhook-Signature header,
+// decodes the event and applies the replay checks. On ErrDuplicateWebhook the returned
+// delivery is still populated.
+func (v *WebhookVerifier) Verify(r *http.Request, body []byte) (*WebhookDelivery, error) {
+	d := &WebhookDelivery{Body: body}
+	secrets := v.secrets
+	if v.provider != nil {
+		var err error
+		secrets, err = v.provider(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSecretUnavailable, err)
+		}
+		if len(secrets) == 0 {
+			return nil, ErrInvalidSignature
+		}
+	}
+	if !v.unsigned {
+		matched, ok := VerifySignatureAny(secrets, body, r.Header.Get("X-Webhook-Signature"))
+		if !ok {
+			return nil, ErrInvalidSignature
+		}
+		d.KeyID = matched.ID
+	}
+	if err := json.Unmarshal(body, &d.Event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	if v.tolerance > 0 {
+		skew := v.now().Sub(d.Event.Timestamp)
+		if skew < 0 {
+			skew = -skew
+		}
+		if d.Event.Timestamp.IsZero() || skew > v.tolerance {
+			return nil, ErrStaleWebhook
+		}
+	}
+	d.Key = d.Event.ID + "|" + d.Event.Timestamp.UTC().Format(time.RFC3339Nano)
+	if v.store != nil && !v.store.Add(d.Key) {
+		return d, ErrDuplicateWebhook
+	}
+	return d, nil
+}
+
+// Forget removes a delivery from the store so that a redelivery is processed again.
+// Call it when handling an accepted delivery fails.
+func (v *WebhookVerifier) Forget(d *WebhookDelivery) {
+	if v.store != nil && d != nil {
+		v.store.Remove(d.Key)
+	}
+}
+
+// Handler returns an http.Handler that verifies deliveries before calling next.
+// Invalid signatures get 401, malformed or stale payloads 400, and duplicates are
+// acknowledged with 204 without calling next. If the secrets cannot be resolved,
+// 503 is returned. The verified delivery is available to next via
+// WebhookDeliveryFromContext, and the request body is restored.
+// If next responds with a 5xx status, the delivery is forgotten so a retry is accepted.
+func (v *WebhookVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		d, err := v.Verify(r, body)
+		if err != nil {
+			code := webhookVerifyStatus(err)
+			if code == http.StatusNoContent {
+				w.WriteHeader(code)
+				return
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), webhookDeliveryKey{}, d))
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		if sw.status >= 500 {
+			v.Forget(d)
+		}
+	})
+}
+
+type webhookDeliveryKey struct{}
+
+// WebhookDeliveryFromContext returns the delivery verified by WebhookVerifier.Handler or WebhookRouter.
+func WebhookDeliveryFromContext(ctx context.Context) (*WebhookDelivery, bool) {
+	d, ok := ctx.Value(webhookDeliveryKey{}).(*WebhookDelivery)
+	return d, ok
+}
+
+// webhookVerifyStatus maps a verification error to an HTTP status code.
+func webhookVerifyStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrDuplicateWebhook):
+		return http.StatusNoContent
+	case errors.Is(err, ErrSecretUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+// statusWriter records the status code written by a handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
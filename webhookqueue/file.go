@@ -0,0 +1,221 @@
+package webhookqueue
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// compactAfter is the number of acknowledgements after which FileQueue rewrites its log.
+const compactAfter = 1000
+
+// logRecord is a line of the FileQueue log.
+type logRecord struct {
+	Op      string   `json:"op"` // "put" or "ack"
+	ID      string   `json:"id,omitempty"`
+	Message *Message `json:"message,omitempty"`
+}
+
+// FileQueue is a durable Queue backed by an append-only JSONL log.
+// Enqueue syncs the log to disk before returning. Messages that were dequeued
+// but not acknowledged before a crash are delivered again when the queue is
+// reopened. The log is compacted on open and periodically as messages are acknowledged.
+type FileQueue struct {
+	path  string
+	p     *pending
+	f     *os.File
+	acked int
+}
+
+var _ Queue = (*FileQueue)(nil)
+
+// OpenFileQueue opens the queue stored at path, creating it if needed.
+// A partially written last line, e.g. after a crash, is discarded.
+func OpenFileQueue(path string) (*FileQueue, error) {
+	q := &FileQueue{path: path, p: newPending()}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *FileQueue) load() error {
+	f, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without a trailing newline is an incomplete write.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("webhookqueue: %s:%d: %w", q.path, n, err)
+		}
+		switch rec.Op {
+		case "put":
+			if rec.Message != nil {
+				q.p.add(*rec.Message)
+			}
+		case "ack":
+			q.p.remove(rec.ID)
+		default:
+			return fmt.Errorf("webhookqueue: %s:%d: unknown op %q", q.path, n, rec.Op)
+		}
+	}
+}
+
+// compact rewrites the log with the pending messages only. The caller holds q.p.mu
+// or has exclusive access.
+func (q *FileQueue) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, e := range q.p.entries {
+		if err := writeRecord(w, logRecord{Op: "put", Message: &e.msg}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if q.f != nil {
+		q.f.Close()
+		q.f = nil
+	}
+	if err := os.Rename(tmp.Name(), q.path); err != nil {
+		return err
+	}
+	q.f, err = os.OpenFile(q.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	q.acked = 0
+	return err
+}
+
+func writeRecord(w io.Writer, rec logRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// append writes rec to the log, syncing it if sync is set. If that fails, the
+// log is truncated to its previous size, so that a partially written line
+// cannot corrupt the records appended after it. The caller holds q.p.mu.
+func (q *FileQueue) append(rec logRecord, sync bool) error {
+	fi, err := q.f.Stat()
+	if err != nil {
+		return err
+	}
+	err = writeRecord(q.f, rec)
+	if err == nil && sync {
+		err = q.f.Sync()
+	}
+	if err != nil {
+		if terr := q.f.Truncate(fi.Size()); terr != nil {
+			return errors.Join(err, terr)
+		}
+	}
+	return err
+}
+
+// Enqueue implements Queue.
+func (q *FileQueue) Enqueue(ctx context.Context, m Message) error {
+	q.p.mu.Lock()
+	defer q.p.mu.Unlock()
+	if q.p.closed {
+		return ErrClosed
+	}
+	if _, ok := q.p.byID[m.ID]; ok {
+		return nil
+	}
+	if err := q.append(logRecord{Op: "put", Message: &m}, true); err != nil {
+		return err
+	}
+	q.p.add(m)
+	return nil
+}
+
+// Dequeue implements Queue.
+func (q *FileQueue) Dequeue(ctx context.Context) (Message, error) { return q.p.dequeue(ctx) }
+
+// Ack implements Queue.
+func (q *FileQueue) Ack(ctx context.Context, id string) error {
+	q.p.mu.Lock()
+	defer q.p.mu.Unlock()
+	if q.p.closed {
+		return ErrClosed
+	}
+	if !q.p.remove(id) {
+		return nil
+	}
+	// Not synced: losing an ack only causes a redelivery.
+	if err := q.append(logRecord{Op: "ack", ID: id}, false); err != nil {
+		return err
+	}
+	q.acked++
+	if q.acked >= compactAfter {
+		return q.compact()
+	}
+	return nil
+}
+
+// Release implements Queue.
+func (q *FileQueue) Release(ctx context.Context, id string) error {
+	q.p.release(id)
+	return nil
+}
+
+// Messages returns the pending messages, including those in flight, oldest first.
+func (q *FileQueue) Messages() []Message { return q.p.messages() }
+
+// Close unblocks pending Dequeue calls and closes the log file.
+func (q *FileQueue) Close() error {
+	q.p.close()
+	q.p.mu.Lock()
+	defer q.p.mu.Unlock()
+	if q.f == nil {
+		return nil
+	}
+	err := q.f.Close()
+	q.f = nil
+	return err
+}
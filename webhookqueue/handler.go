@@ -0,0 +1,39 @@
+package webhookqueue
+
+import (
+	"net/http"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// NewHandler returns an http.Handler that verifies webhook deliveries with
+// cursor.NewWebhookVerifier(secret, opts...), enqueues them to q and responds
+// with 202 without waiting for processing. Verification failures get the same
+// responses as cursor.WebhookVerifier.Handler. If the event cannot be enqueued,
+// it responds with 503 so that the sender retries. Like NewWebhookVerifier, it
+// returns cursor.ErrNoWebhookSecret if secret is empty and no other secret or
+// opt-out is passed in opts.
+func NewHandler(q Queue, secret string, opts ...cursor.VerifyOption) (http.Handler, error) {
+	v, err := cursor.NewWebhookVerifier(secret, opts...)
+	if err != nil {
+		return nil, err
+	}
+	verified := v.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, _ := cursor.WebhookDeliveryFromContext(r.Context())
+		m := Message{ID: d.Key, Event: d.Event, ReceivedAt: time.Now().UTC()}
+		if err := q.Enqueue(r.Context(), m); err != nil {
+			http.Error(w, "failed to enqueue webhook", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		verified.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,267 @@
+package webhookqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// PoolOption configures a WorkerPool.
+type PoolOption func(*WorkerPool)
+
+// WithWorkers sets the number of concurrent workers (default 4).
+func WithWorkers(n int) PoolOption {
+	return func(p *WorkerPool) {
+		if n > 0 {
+			p.workers = n
+		}
+	}
+}
+
+// WithMaxAttempts sets how many times a message is handled before it is
+// dead-lettered (default 5).
+func WithMaxAttempts(n int) PoolOption {
+	return func(p *WorkerPool) {
+		if n > 0 {
+			p.maxAttempts = n
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled on every further
+// retry up to max (defaults 1s and 1m).
+func WithBackoff(initial, max time.Duration) PoolOption {
+	return func(p *WorkerPool) {
+		p.initialBackoff = initial
+		p.maxBackoff = max
+	}
+}
+
+// WithDeadLetter sets the queue receiving messages that failed every attempt.
+// Without it, such messages are dropped after OnError is called.
+func WithDeadLetter(q Queue) PoolOption {
+	return func(p *WorkerPool) { p.deadLetter = q }
+}
+
+// WithErrorHandler sets a callback invoked for every failed attempt, e.g. for
+// logging, and for every failure to move a message to the dead-letter queue.
+func WithErrorHandler(fn func(m Message, attempt int, err error)) PoolOption {
+	return func(p *WorkerPool) { p.onErr = fn }
+}
+
+// WorkerPool processes queued webhook events.
+//
+// Events of the same agent are handled one at a time in the order they were
+// enqueued, so a failing event delays later events of its agent until it
+// succeeds or is dead-lettered. If the dead-letter queue rejects a message, it
+// is retried with backoff and keeps blocking its agent. Events of different
+// agents are handled concurrently, and a busy agent never delays dispatching
+// to the other workers. A message is acknowledged after it is handled or moved
+// to the dead-letter queue; messages still in flight when Run returns are
+// released back to the queue.
+type WorkerPool struct {
+	queue   Queue
+	handler cursor.WebhookHandlerFunc
+
+	workers        int
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	deadLetter     Queue
+	onErr          func(m Message, attempt int, err error)
+}
+
+// NewWorkerPool creates a pool handling messages from q with h.
+// A WebhookRouter can be used as the handler via its Dispatch method.
+func NewWorkerPool(q Queue, h cursor.WebhookHandlerFunc, opts ...PoolOption) *WorkerPool {
+	p := &WorkerPool{
+		queue:          q,
+		handler:        h,
+		workers:        4,
+		maxAttempts:    5,
+		initialBackoff: time.Second,
+		maxBackoff:     time.Minute,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run processes messages until ctx ends or the queue is closed.
+// It returns nil when the queue is closed, otherwise the context error.
+func (p *WorkerPool) Run(ctx context.Context) error {
+	shards := make([]*shard, p.workers)
+	var wg sync.WaitGroup
+	for i := range shards {
+		shards[i] = newShard()
+		wg.Add(1)
+		go func(s *shard) {
+			defer wg.Done()
+			p.work(ctx, s)
+		}(shards[i])
+	}
+
+	var err error
+	for {
+		var m Message
+		m, err = p.queue.Dequeue(ctx)
+		if err != nil {
+			break
+		}
+		shards[shardOf(m.Event.ID, len(shards))].push(m)
+	}
+	for _, s := range shards {
+		s.close()
+	}
+	wg.Wait()
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func shardOf(agentID string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(agentID))
+	return int(h.Sum32() % uint32(n))
+}
+
+// shard is the FIFO of dequeued messages handled by one worker. It is
+// unbounded so that the dispatcher never waits for a busy worker; its messages
+// are in flight in the queue, so it holds no more than the queue itself.
+type shard struct {
+	mu     sync.Mutex
+	msgs   []Message
+	closed bool
+	ready  chan struct{} // signaled after push and close
+}
+
+func newShard() *shard {
+	return &shard{ready: make(chan struct{}, 1)}
+}
+
+func (s *shard) push(m Message) {
+	s.mu.Lock()
+	s.msgs = append(s.msgs, m)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *shard) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *shard) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop waits for the next message. It reports false once the shard is closed
+// and empty. Only the shard's worker may call it.
+func (s *shard) pop() (Message, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.msgs) > 0 {
+			m := s.msgs[0]
+			s.msgs[0] = Message{}
+			s.msgs = s.msgs[1:]
+			s.mu.Unlock()
+			return m, true
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return Message{}, false
+		}
+		<-s.ready
+	}
+}
+
+// work handles the messages of one shard in order.
+func (p *WorkerPool) work(ctx context.Context, s *shard) {
+	for {
+		m, ok := s.pop()
+		if !ok {
+			return
+		}
+		if ctx.Err() != nil {
+			p.queue.Release(context.WithoutCancel(ctx), m.ID)
+			continue
+		}
+		p.process(ctx, m)
+	}
+}
+
+func (p *WorkerPool) process(ctx context.Context, m Message) {
+	backoff := p.initialBackoff
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				p.queue.Release(context.WithoutCancel(ctx), m.ID)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, p.maxBackoff)
+		}
+		if err = p.handle(ctx, &m); err == nil {
+			p.queue.Ack(context.WithoutCancel(ctx), m.ID)
+			return
+		}
+		if p.onErr != nil {
+			p.onErr(m, attempt, err)
+		}
+		if ctx.Err() != nil {
+			p.queue.Release(context.WithoutCancel(ctx), m.ID)
+			return
+		}
+	}
+
+	if p.deadLetter != nil {
+		dead := m
+		dead.Attempts = p.maxAttempts
+		dead.LastError = err.Error()
+		// Keep the message at the head of its shard until it is dead-lettered,
+		// so that later events of the agent are not handled before it.
+		for {
+			derr := p.deadLetter.Enqueue(context.WithoutCancel(ctx), dead)
+			if derr == nil {
+				break
+			}
+			if p.onErr != nil {
+				p.onErr(m, p.maxAttempts, fmt.Errorf("webhookqueue: dead-letter: %w", derr))
+			}
+			select {
+			case <-ctx.Done():
+				p.queue.Release(context.WithoutCancel(ctx), m.ID)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, p.maxBackoff)
+		}
+	}
+	p.queue.Ack(context.WithoutCancel(ctx), m.ID)
+}
+
+func (p *WorkerPool) handle(ctx context.Context, m *Message) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &cursor.WebhookPanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	ev := m.Event
+	return p.handler(ctx, &ev)
+}
@@ -0,0 +1,203 @@
+// Package webhookqueue decouples receiving webhooks from processing them.
+//
+// NewHandler verifies a delivery, persists it to a Queue and acknowledges it
+// right away with 202. A WorkerPool then processes queued events with retries,
+// per-agent ordering and a dead-letter queue. Messages stay in the queue until
+// they are acknowledged, so with a durable queue (FileQueue) a crash between
+// receipt and handling does not lose events: they are processed again after a
+// restart. Handlers must therefore be idempotent.
+package webhookqueue
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+)
+
+// ErrClosed is returned by operations on a closed queue.
+var ErrClosed = errors.New("webhookqueue: queue closed")
+
+// Message is a queued webhook event.
+type Message struct {
+	// ID identifies the delivery (agent ID + event timestamp); enqueueing an ID
+	// that is already pending is a no-op.
+	ID         string              `json:"id"`
+	Event      cursor.WebhookEvent `json:"event"`
+	ReceivedAt time.Time           `json:"receivedAt"`
+	// Attempts and LastError are set on messages moved to a dead-letter queue.
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Queue stores messages until they are acknowledged.
+// Implementations must be safe for concurrent use.
+type Queue interface {
+	// Enqueue stores m. It must not return before m is persisted.
+	Enqueue(ctx context.Context, m Message) error
+	// Dequeue blocks until a message is available or ctx ends and returns the
+	// oldest message that is not in flight. The message stays in the queue
+	// until it is acknowledged or released.
+	Dequeue(ctx context.Context) (Message, error)
+	// Ack removes a dequeued message.
+	Ack(ctx context.Context, id string) error
+	// Release makes a dequeued message available again, in its original position.
+	Release(ctx context.Context, id string) error
+}
+
+// entry is a pending message.
+type entry struct {
+	msg      Message
+	seq      uint64
+	inFlight bool
+}
+
+// pending is the in-memory state shared by MemoryQueue and FileQueue.
+type pending struct {
+	mu      sync.Mutex
+	entries []*entry // ordered by seq
+	byID    map[string]*entry
+	seq     uint64
+	wake    chan struct{}
+	closed  bool
+}
+
+func newPending() *pending {
+	return &pending{byID: make(map[string]*entry), wake: make(chan struct{})}
+}
+
+// add appends m unless it is already pending. The caller holds p.mu.
+func (p *pending) add(m Message) bool {
+	if _, ok := p.byID[m.ID]; ok {
+		return false
+	}
+	p.seq++
+	e := &entry{msg: m, seq: p.seq}
+	p.entries = append(p.entries, e)
+	p.byID[m.ID] = e
+	p.notify()
+	return true
+}
+
+// remove drops id. The caller holds p.mu.
+func (p *pending) remove(id string) bool {
+	e, ok := p.byID[id]
+	if !ok {
+		return false
+	}
+	delete(p.byID, id)
+	p.entries = slices.DeleteFunc(p.entries, func(x *entry) bool { return x == e })
+	return true
+}
+
+// notify wakes blocked Dequeue calls. The caller holds p.mu.
+func (p *pending) notify() {
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+func (p *pending) dequeue(ctx context.Context) (Message, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return Message{}, ErrClosed
+		}
+		for _, e := range p.entries {
+			if !e.inFlight {
+				e.inFlight = true
+				p.mu.Unlock()
+				return e.msg, nil
+			}
+		}
+		wake := p.wake
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+func (p *pending) release(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.byID[id]; ok && e.inFlight {
+		e.inFlight = false
+		p.notify()
+	}
+}
+
+func (p *pending) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		p.notify()
+	}
+}
+
+func (p *pending) messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Message, len(p.entries))
+	for i, e := range p.entries {
+		out[i] = e.msg
+	}
+	return out
+}
+
+// MemoryQueue is a Queue kept in memory. Messages are lost when the process
+// exits; use FileQueue when events must survive a crash.
+type MemoryQueue struct {
+	p *pending
+}
+
+var _ Queue = (*MemoryQueue)(nil)
+
+// NewMemoryQueue creates an empty in-memory queue.
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{p: newPending()}
+}
+
+// Enqueue implements Queue.
+func (q *MemoryQueue) Enqueue(ctx context.Context, m Message) error {
+	q.p.mu.Lock()
+	defer q.p.mu.Unlock()
+	if q.p.closed {
+		return ErrClosed
+	}
+	q.p.add(m)
+	return nil
+}
+
+// Dequeue implements Queue.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (Message, error) { return q.p.dequeue(ctx) }
+
+// Ack implements Queue.
+func (q *MemoryQueue) Ack(ctx context.Context, id string) error {
+	q.p.mu.Lock()
+	defer q.p.mu.Unlock()
+	q.p.remove(id)
+	return nil
+}
+
+// Release implements Queue.
+func (q *MemoryQueue) Release(ctx context.Context, id string) error {
+	q.p.release(id)
+	return nil
+}
+
+// Messages returns the pending messages, including those in flight, oldest first.
+func (q *MemoryQueue) Messages() []Message { return q.p.messages() }
+
+// Close unblocks pending Dequeue calls; further operations return ErrClosed.
+func (q *MemoryQueue) Close() error {
+	q.p.close()
+	return nil
+}
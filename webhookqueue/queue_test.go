@@ -0,0 +1,240 @@
+package webhookqueue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cursor "github.com/unkn0wncode/cursor-go-sdk"
+	"github.com/unkn0wncode/cursor-go-sdk/cursortest"
+	"github.com/unkn0wncode/cursor-go-sdk/webhookqueue"
+)
+
+const secret = "webhook-secret-0123456789abcdef0123"
+
+func message(agentID string, status cursor.AgentStatus, n int) webhookqueue.Message {
+	ts := time.Date(2025, 6, 1, 12, 0, n, 0, time.UTC)
+	return webhookqueue.Message{
+		ID:    agentID + "|" + ts.Format(time.RFC3339Nano),
+		Event: cursor.WebhookEvent{Event: cursor.WebhookEventStatusChange, ID: agentID, Status: status, Timestamp: ts},
+	}
+}
+
+func TestHandlerEnqueues(t *testing.T) {
+	q := webhookqueue.NewMemoryQueue()
+	h, err := webhookqueue.NewHandler(q, secret)
+	require.NoError(t, err)
+	sim := &cursortest.WebhookSimulator{Secret: secret, Handler: h}
+	events := sim.Lifecycle(cursor.Agent{ID: "bc_1", Status: cursor.AgentStatusFinished})
+
+	deliveries, err := sim.Deliver(context.Background(), events...)
+	require.NoError(t, err)
+	for _, d := range deliveries {
+		require.Equal(t, http.StatusAccepted, d.StatusCode)
+	}
+	// Redelivery is acknowledged without enqueueing twice.
+	_, err = sim.Deliver(context.Background(), events[0])
+	require.NoError(t, err)
+	require.Len(t, q.Messages(), 3)
+
+	bad := &cursortest.WebhookSimulator{Secret: "wrong", Handler: h}
+	deliveries, _ = bad.Deliver(context.Background(), events[0])
+	require.Equal(t, http.StatusUnauthorized, deliveries[0].StatusCode)
+}
+
+func TestWorkerPool(t *testing.T) {
+	q := webhookqueue.NewMemoryQueue()
+	dead := webhookqueue.NewMemoryQueue()
+	ctx := context.Background()
+	for i, st := range []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, cursor.AgentStatusFinished} {
+		require.NoError(t, q.Enqueue(ctx, message("bc_a", st, i)))
+		require.NoError(t, q.Enqueue(ctx, message("bc_b", st, i)))
+	}
+	require.NoError(t, q.Enqueue(ctx, message("bc_poison", cursor.AgentStatusError, 0)))
+
+	var mu sync.Mutex
+	seen := map[string][]cursor.AgentStatus{}
+	failedOnce := false
+	var failures int
+	pool := webhookqueue.NewWorkerPool(q, func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case ev.ID == "bc_poison":
+			panic("cannot handle")
+		case ev.ID == "bc_a" && ev.Status == cursor.AgentStatusRunning && !failedOnce:
+			failedOnce = true
+			return errors.New("temporary failure")
+		}
+		seen[ev.ID] = append(seen[ev.ID], ev.Status)
+		return nil
+	},
+		webhookqueue.WithWorkers(3),
+		webhookqueue.WithMaxAttempts(2),
+		webhookqueue.WithBackoff(time.Millisecond, time.Millisecond),
+		webhookqueue.WithDeadLetter(dead),
+		webhookqueue.WithErrorHandler(func(m webhookqueue.Message, attempt int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures++
+		}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx) }()
+	require.Eventually(t, func() bool { return len(q.Messages()) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, q.Close())
+	require.NoError(t, <-done)
+
+	lifecycle := []cursor.AgentStatus{cursor.AgentStatusCreating, cursor.AgentStatusRunning, cursor.AgentStatusFinished}
+	require.Equal(t, lifecycle, seen["bc_a"])
+	require.Equal(t, lifecycle, seen["bc_b"])
+
+	dl := dead.Messages()
+	require.Len(t, dl, 1)
+	require.Equal(t, "bc_poison", dl[0].Event.ID)
+	require.Equal(t, 2, dl[0].Attempts)
+	require.Contains(t, dl[0].LastError, "cannot handle")
+	require.Equal(t, 3, failures) // one retried failure, two attempts of the poison message
+}
+
+func TestWorkerPoolBusyAgentDoesNotBlockOthers(t *testing.T) {
+	q := webhookqueue.NewMemoryQueue()
+	ctx := context.Background()
+	// bc_slow and bc_fast are handled by different workers.
+	for i := range 40 {
+		require.NoError(t, q.Enqueue(ctx, message("bc_slow", cursor.AgentStatusRunning, i)))
+	}
+	require.NoError(t, q.Enqueue(ctx, message("bc_fast", cursor.AgentStatusFinished, 0)))
+
+	unblock := make(chan struct{})
+	fast := make(chan struct{})
+	pool := webhookqueue.NewWorkerPool(q, func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		if ev.ID == "bc_fast" {
+			close(fast)
+			return nil
+		}
+		<-unblock
+		return nil
+	}, webhookqueue.WithWorkers(2))
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx) }()
+
+	select {
+	case <-fast:
+	case <-time.After(time.Second):
+		t.Fatal("bc_fast was not handled while bc_slow was busy")
+	}
+	close(unblock)
+	require.Eventually(t, func() bool { return len(q.Messages()) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, q.Close())
+	require.NoError(t, <-done)
+}
+
+// flakyQueue fails the first failures calls to Enqueue.
+type flakyQueue struct {
+	*webhookqueue.MemoryQueue
+	mu       sync.Mutex
+	failures int
+}
+
+func (q *flakyQueue) Enqueue(ctx context.Context, m webhookqueue.Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.failures > 0 {
+		q.failures--
+		return errors.New("disk full")
+	}
+	return q.MemoryQueue.Enqueue(ctx, m)
+}
+
+func TestWorkerPoolDeadLetterFailureKeepsOrder(t *testing.T) {
+	q := webhookqueue.NewMemoryQueue()
+	dead := &flakyQueue{MemoryQueue: webhookqueue.NewMemoryQueue(), failures: 2}
+	ctx := context.Background()
+	require.NoError(t, q.Enqueue(ctx, message("bc_1", cursor.AgentStatusRunning, 0)))
+	require.NoError(t, q.Enqueue(ctx, message("bc_1", cursor.AgentStatusFinished, 1)))
+
+	var mu sync.Mutex
+	var deadBeforeFinished int
+	var errs []error
+	pool := webhookqueue.NewWorkerPool(q, func(ctx context.Context, ev *cursor.WebhookEvent) error {
+		if ev.Status == cursor.AgentStatusRunning {
+			return errors.New("cannot handle")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		deadBeforeFinished = len(dead.Messages())
+		return nil
+	},
+		webhookqueue.WithMaxAttempts(1),
+		webhookqueue.WithBackoff(time.Millisecond, time.Millisecond),
+		webhookqueue.WithDeadLetter(dead),
+		webhookqueue.WithErrorHandler(func(m webhookqueue.Message, attempt int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx) }()
+	require.Eventually(t, func() bool { return len(q.Messages()) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, q.Close())
+	require.NoError(t, <-done)
+
+	require.Equal(t, 1, deadBeforeFinished, "the failed event is dead-lettered before the next one is handled")
+	require.Len(t, errs, 3) // the handler failure and two dead-letter failures
+	require.ErrorContains(t, errs[2], "disk full")
+}
+
+func TestFileQueueSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "webhooks.jsonl")
+	q, err := webhookqueue.OpenFileQueue(path)
+	require.NoError(t, err)
+	m1 := message("bc_1", cursor.AgentStatusRunning, 0)
+	m2 := message("bc_1", cursor.AgentStatusFinished, 1)
+	require.NoError(t, q.Enqueue(ctx, m1))
+	require.NoError(t, q.Enqueue(ctx, m2))
+
+	got, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, m1.ID, got.ID)
+	require.NoError(t, q.Ack(ctx, got.ID))
+	got, err = q.Dequeue(ctx) // in flight when the process "crashes"
+	require.NoError(t, err)
+	require.Equal(t, m2.ID, got.ID)
+	require.NoError(t, q.Close())
+
+	// Simulate a torn write at the end of the log.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put","mess`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	q, err = webhookqueue.OpenFileQueue(path)
+	require.NoError(t, err)
+	defer q.Close()
+	msgs := q.Messages()
+	require.Len(t, msgs, 1)
+	require.Equal(t, m2.ID, msgs[0].ID)
+	require.Equal(t, cursor.AgentStatusFinished, msgs[0].Event.Status)
+
+	got, err = q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Release(ctx, got.ID))
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	got, err = q.Dequeue(cctx)
+	require.NoError(t, err)
+	require.Equal(t, m2.ID, got.ID)
+	_, err = q.Dequeue(cctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -11,17 +11,23 @@ import (
 	"strings"
 )
 
+// signaturePrefix precedes the hex-encoded HMAC in the X-Webhook-Signature header.
+const signaturePrefix = "sha256="
+
+// Sign returns the X-Webhook-Signature header value for body: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
 // VerifySignature validates the HMAC-SHA256 signature header against the raw body and secret.
 func VerifySignature(secret string, body []byte, signatureHeader string) bool {
-	const prefix = "sha256="
-	if !strings.HasPrefix(signatureHeader, prefix) {
+	if !strings.HasPrefix(signatureHeader, signaturePrefix) {
 		return false
 	}
-	expected := signatureHeader[len(prefix):]
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(body)
-	computed := hex.EncodeToString(mac.Sum(nil))
-	return subtle.ConstantTimeCompare([]byte(expected), []byte(computed)) == 1
+	return subtle.ConstantTimeCompare([]byte(signatureHeader), []byte(Sign(secret, body))) == 1
 }
 
 // SignatureHandleWrapper returns an http.Handler that verifies webhook signatures.